@@ -0,0 +1,81 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+)
+
+// Future holds the eventual outcome of a transaction started by ExecuteAsync
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// Get blocks until the transaction completes and returns its result, or returns
+// ctx.Err() if ctx is done first. Every call returns the same result.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed once the transaction has completed
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// ExecuteAsync runs a transaction in a new goroutine and returns a Future for its
+// result. A panic inside txFunc is rolled back and reported as an error by Get.
+func ExecuteAsync[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options ...ConfigOption) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+
+	go func() {
+		defer close(f.done)
+
+		if err := ctx.Err(); err != nil {
+			f.err = err
+			return
+		}
+
+		f.value, f.err = executeRecovered(ctx, db, txFunc, options)
+	}()
+
+	return f
+}
+
+// Result holds the outcome of a transaction started by ExecuteAsyncChan
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// ExecuteAsyncChan is the channel-based form of ExecuteAsync that ExecuteAsync
+// returned before it switched to Future. The returned channel receives exactly one
+// Result before being closed, and is buffered so the goroutine never blocks.
+func ExecuteAsyncChan[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options ...ConfigOption) <-chan Result[T] {
+	ch := make(chan Result[T], 1)
+	future := ExecuteAsync(ctx, db, txFunc, options...)
+
+	go func() {
+		defer close(ch)
+		<-future.Done()
+		ch <- Result[T]{Value: future.value, Err: future.err}
+	}()
+
+	return ch
+}
+
+// executeRecovered calls ExecuteContext and converts a re-raised panic into an error
+func executeRecovered[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options []ConfigOption) (result T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("transaction panicked: %v", p)
+		}
+	}()
+	return ExecuteContext(ctx, db, txFunc, options...)
+}
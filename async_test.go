@@ -0,0 +1,139 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteAsync_DeliversResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	calls := 0
+	future := ExecuteAsync(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		calls++
+		return 9, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		value, err := future.Get(context.Background())
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if value != 9 {
+			t.Errorf("expected value 9, got %v", value)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the transaction to run once, got %d", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteAsync_CancelledContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = ExecuteAsync(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		t.Error("expected transaction function not to be called")
+		return nil, nil
+	}).Get(context.Background())
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFuture_GetRespectsContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	release := make(chan struct{})
+	future := ExecuteAsync(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := future.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+
+	close(release)
+	<-future.Done()
+	if value, err := future.Get(context.Background()); err != nil || value != 1 {
+		t.Errorf("expected 1 and no error, got %v and %v", value, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteAsyncChan_DeliversResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	ch := ExecuteAsyncChan(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 9, nil
+	})
+
+	res := <-ch
+	if res.Err != nil {
+		t.Errorf("expected no error, got %v", res.Err)
+	}
+	if res.Value != 9 {
+		t.Errorf("expected value 9, got %v", res.Value)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("expected the channel to be closed after the result")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Default audit column names used by AuditedInsert and AuditedUpdate
+const (
+	DefaultCreatedAtColumn = "created_at"
+	DefaultUpdatedAtColumn = "updated_at"
+)
+
+// auditColumns holds the audit column names of a transaction
+type auditColumns struct {
+	createdAt string
+	updatedAt string
+}
+
+// txAuditColumns maps each transaction started with WithAuditColumns to its column names
+var txAuditColumns sync.Map
+
+// WithAuditColumns sets the columns AuditedInsert and AuditedUpdate fill in within the
+// transaction. Without it they use created_at and updated_at.
+func WithAuditColumns(createdAtCol, updatedAtCol string) ConfigOption {
+	return func(c *Config) {
+		c.CreatedAtColumn = createdAtCol
+		c.UpdatedAtColumn = updatedAtCol
+	}
+}
+
+// AuditedInsert inserts row into table with both audit columns set to time.Now().UTC().
+// Columns come from the struct's db tags; the audit columns are added if the struct
+// does not map them. When row is a pointer, its audit fields are updated as well.
+func AuditedInsert(ctx context.Context, tx *sqlx.Tx, table string, row any) (sql.Result, error) {
+	audit := auditColumnsFor(tx)
+	columns, args, err := auditedArgs(tx, table, row, time.Now().UTC(), audit.createdAt, audit.updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (:%s)", table, strings.Join(columns, ", "), strings.Join(columns, ", :"))
+	res, err := tx.NamedExecContext(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert into %s: %w", table, err)
+	}
+	return res, nil
+}
+
+// AuditedUpdate updates the row of table identified by row's pkCol field, setting every
+// other mapped column and the updated-at column to time.Now().UTC(). The created-at
+// column is never changed. When row is a pointer, its updated-at field is updated as well.
+func AuditedUpdate(ctx context.Context, tx *sqlx.Tx, table string, row any, pkCol string) (sql.Result, error) {
+	audit := auditColumnsFor(tx)
+	columns, args, err := auditedArgs(tx, table, row, time.Now().UTC(), audit.updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := args[pkCol]; !ok {
+		return nil, fmt.Errorf("%T must map %q", row, pkCol)
+	}
+
+	var sets []string
+	for _, column := range columns {
+		if column != pkCol && column != audit.createdAt {
+			sets = append(sets, fmt.Sprintf("%s = :%s", column, column))
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = :%s", table, strings.Join(sets, ", "), pkCol, pkCol)
+	res, err := tx.NamedExecContext(ctx, query, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", table, err)
+	}
+	return res, nil
+}
+
+// auditedArgs returns the columns and named arguments for row with each of auditCols
+// set to now, writing now back to row's matching fields when they are settable
+func auditedArgs(tx *sqlx.Tx, table string, row any, now time.Time, auditCols ...string) ([]string, map[string]any, error) {
+	if err := validateIdentifier("table", table); err != nil {
+		return nil, nil, err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(row))
+	columns, err := structColumns(value.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := make(map[string]any, len(columns)+len(auditCols))
+	for _, column := range columns {
+		args[column] = tx.Mapper.FieldByName(value, column).Interface()
+	}
+	for _, column := range auditCols {
+		if _, ok := args[column]; !ok {
+			columns = append(columns, column)
+		}
+		args[column] = now
+		if field := tx.Mapper.FieldByName(value, column); field.CanSet() && field.Type() == reflect.TypeOf(now) {
+			field.Set(reflect.ValueOf(now))
+		}
+	}
+	return columns, args, nil
+}
+
+// auditColumnsFor returns the audit columns configured for tx, or the defaults
+func auditColumnsFor(tx *sqlx.Tx) auditColumns {
+	if audit, ok := txAuditColumns.Load(tx); ok {
+		return audit.(auditColumns)
+	}
+	return auditColumns{createdAt: DefaultCreatedAtColumn, updatedAt: DefaultUpdatedAtColumn}
+}
+
+// trackAuditColumns makes the configured audit columns known for tx until the
+// returned function is called
+func trackAuditColumns(tx *sqlx.Tx, config *Config) func() {
+	if config.CreatedAtColumn == "" && config.UpdatedAtColumn == "" {
+		return func() {}
+	}
+	txAuditColumns.Store(tx, auditColumns{createdAt: config.CreatedAtColumn, updatedAt: config.UpdatedAtColumn})
+	return func() { txAuditColumns.Delete(tx) }
+}
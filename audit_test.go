@@ -0,0 +1,131 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type auditedUser struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func TestAuditedInsert(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4)").
+		WithArgs(1, "alice", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := &auditedUser{ID: 1, Name: "alice"}
+	before := time.Now().UTC()
+	err = ExecuteVoidContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) error {
+		_, err := AuditedInsert(context.Background(), tx, "users", user)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if user.CreatedAt.Before(before) || !user.CreatedAt.Equal(user.UpdatedAt) {
+		t.Errorf("expected both audit fields to be set to the same current time, got %v and %v", user.CreatedAt, user.UpdatedAt)
+	}
+	if user.CreatedAt.Location() != time.UTC {
+		t.Errorf("expected UTC timestamps, got %v", user.CreatedAt.Location())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAuditedUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET name = $1, updated_at = $2 WHERE id = $3").
+		WithArgs("bob", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := &auditedUser{ID: 1, Name: "bob", CreatedAt: created, UpdatedAt: created}
+	err = ExecuteVoidContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) error {
+		_, err := AuditedUpdate(context.Background(), tx, "users", user, "id")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !user.CreatedAt.Equal(created) {
+		t.Errorf("expected created_at to be left alone, got %v", user.CreatedAt)
+	}
+	if !user.UpdatedAt.After(created) {
+		t.Errorf("expected updated_at to be refreshed, got %v", user.UpdatedAt)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithAuditColumns(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	type event struct {
+		Name string `db:"name"`
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO events (name, inserted_at, modified_at) VALUES ($1, $2, $3)").
+		WithArgs("signup", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = ExecuteVoidContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) error {
+		_, err := AuditedInsert(context.Background(), tx, "events", event{Name: "signup"})
+		return err
+	}, WithAuditColumns("inserted_at", "modified_at"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithAuditColumns_InvalidName(t *testing.T) {
+	config := newConfig([]ConfigOption{WithAuditColumns("created_at", "updated at")})
+	if err := config.Validate(); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
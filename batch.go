@@ -0,0 +1,73 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BatchError reports which function in a batch failed
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e BatchError) Error() string {
+	return fmt.Sprintf("batch function %d failed: %v", e.Index, e.Err)
+}
+
+func (e BatchError) Unwrap() error {
+	return e.Err
+}
+
+// BatchFailure is the name ExecuteMany documents for BatchError
+type BatchFailure = BatchError
+
+// BatchExecute runs every function in order inside a single transaction.
+// The transaction commits only if all functions succeed; on the first failure it
+// rolls back and returns a BatchError carrying the index of the failing function.
+func BatchExecute[T any](ctx context.Context, db Executer, fns []TxFunc[T], options ...ConfigOption) ([]T, error) {
+	return ExecuteContext(ctx, db, func(tx *sqlx.Tx) ([]T, error) {
+		results := make([]T, 0, len(fns))
+		for i, fn := range fns {
+			result, err := fn(tx)
+			if err != nil {
+				return nil, BatchError{Index: i, Err: err}
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	}, options...)
+}
+
+// ExecuteMany runs heterogeneous functions in order inside a single transaction and
+// returns their results in the same order. It is BatchExecute for TxFunc[any]: on the
+// first failure the transaction rolls back and the error is a BatchFailure whose
+// Index identifies the failing function.
+func ExecuteMany(ctx context.Context, db Executer, fns []TxFunc[any], options ...ConfigOption) ([]any, error) {
+	return BatchExecute(ctx, db, fns, options...)
+}
+
+// ChunkedExecute splits items into chunks of at most chunkSize and runs fn on each
+// chunk in its own transaction, keeping every transaction small. Processing stops at
+// the first failing chunk; the results of the chunks committed so far are returned
+// together with the error so callers can compensate.
+func ChunkedExecute[T, R any](ctx context.Context, db Executer, items []T, chunkSize int, fn func(tx *sqlx.Tx, chunk []T) ([]R, error), options ...ConfigOption) ([]R, error) {
+	if chunkSize <= 0 {
+		return nil, invalidOption("chunk size must be positive, got %d", chunkSize)
+	}
+
+	results := make([]R, 0, len(items))
+	for start := 0; start < len(items); start += chunkSize {
+		chunk := items[start:min(start+chunkSize, len(items))]
+		chunkResults, err := ExecuteContext(ctx, db, func(tx *sqlx.Tx) ([]R, error) {
+			return fn(tx, chunk)
+		}, options...)
+		if err != nil {
+			return results, fmt.Errorf("chunk starting at item %d failed: %w", start, err)
+		}
+		results = append(results, chunkResults...)
+	}
+	return results, nil
+}
@@ -0,0 +1,206 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestBatchExecute_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	results, err := BatchExecute(context.Background(), sqlxDB, []TxFunc[int]{
+		func(tx *sqlx.Tx) (int, error) { return 1, nil },
+		func(tx *sqlx.Tx) (int, error) { return 2, nil },
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(results, want) {
+		t.Errorf("expected results %v, got %v", want, results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBatchExecute_ReportsFailingIndex(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	thirdCalled := false
+	_, err = BatchExecute(context.Background(), sqlxDB, []TxFunc[int]{
+		func(tx *sqlx.Tx) (int, error) { return 1, nil },
+		func(tx *sqlx.Tx) (int, error) { return 0, txErr },
+		func(tx *sqlx.Tx) (int, error) { thirdCalled = true; return 3, nil },
+	})
+
+	var batchErr BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected batch error, got %v", err)
+	}
+	if batchErr.Index != 1 {
+		t.Errorf("expected failing index 1, got %d", batchErr.Index)
+	}
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected error to wrap test error, got %v", err)
+	}
+	if thirdCalled {
+		t.Error("expected functions after the failure not to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChunkedExecute_OneTransactionPerChunk(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	for range 3 {
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+	}
+
+	var chunks [][]int
+	results, err := ChunkedExecute(context.Background(), sqlxDB, []int{1, 2, 3, 4, 5}, 2, func(tx *sqlx.Tx, chunk []int) ([]int, error) {
+		chunks = append(chunks, chunk)
+		doubled := make([]int, len(chunk))
+		for i, v := range chunk {
+			doubled[i] = v * 2
+		}
+		return doubled, nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if want := [][]int{{1, 2}, {3, 4}, {5}}; !reflect.DeepEqual(chunks, want) {
+		t.Errorf("expected chunks %v, got %v", want, chunks)
+	}
+	if want := []int{2, 4, 6, 8, 10}; !reflect.DeepEqual(results, want) {
+		t.Errorf("expected results %v, got %v", want, results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChunkedExecute_StopsAtFailingChunk(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	calls := 0
+	results, err := ChunkedExecute(context.Background(), sqlxDB, []int{1, 2, 3, 4, 5}, 2, func(tx *sqlx.Tx, chunk []int) ([]int, error) {
+		calls++
+		if calls == 2 {
+			return nil, txErr
+		}
+		return chunk, nil
+	})
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 chunks to run, got %d", calls)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(results, want) {
+		t.Errorf("expected partial results %v, got %v", want, results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChunkedExecute_InvalidChunkSize(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ChunkedExecute(context.Background(), sqlxDB, []int{1}, 0, func(tx *sqlx.Tx, chunk []int) ([]int, error) {
+		return chunk, nil
+	})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestExecuteMany_ReportsBatchFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	_, err = ExecuteMany(context.Background(), sqlxDB, []TxFunc[any]{
+		func(tx *sqlx.Tx) (any, error) { return 1, nil },
+		func(tx *sqlx.Tx) (any, error) { return "two", nil },
+		func(tx *sqlx.Tx) (any, error) { return nil, txErr },
+	})
+
+	var bf BatchFailure
+	if !errors.As(err, &bf) {
+		t.Fatalf("expected BatchFailure, got %v", err)
+	}
+	if bf.Index != 2 || !errors.Is(err, txErr) {
+		t.Errorf("expected failure at index 2 wrapping test error, got %+v", bf)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,260 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultBulkBatchSize is the number of rows per INSERT statement when none is configured
+const defaultBulkBatchSize = 1000
+
+// BulkInsertOptions configures BulkInsert
+type BulkInsertOptions struct {
+	// BatchSize is the maximum number of rows per INSERT statement (default 1000). It is
+	// lowered if a batch would exceed the driver's bind parameter limit.
+	BatchSize int
+}
+
+// maxBindParameters returns the number of bind parameters one statement may carry:
+// 65535 on PostgreSQL and MySQL, and 999, SQLite's limit before 3.32.0, on SQLite and
+// unrecognized drivers
+func maxBindParameters(driverName string) int {
+	if isPostgres(driverName) || isMySQL(driverName) {
+		return 65535
+	}
+	return 999
+}
+
+// bulkBatchSize returns the rows per statement: configured, or defaultBulkBatchSize if
+// it is not positive, capped so that a batch of rows with the given number of columns
+// stays within the bind parameter limit of driverName
+func bulkBatchSize(configured, columns int, driverName string) int {
+	if configured <= 0 {
+		configured = defaultBulkBatchSize
+	}
+	return max(1, min(configured, maxBindParameters(driverName)/columns))
+}
+
+// bulkResult aggregates the results of several statements
+type bulkResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r bulkResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r bulkResult) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+// BulkInsert inserts rows into table using multi-row INSERT statements built from the
+// struct's db tags. Rows are sent in batches of at most BatchSize; placeholders follow
+// the bind style of tx.DriverName(). The returned result sums RowsAffected over all
+// batches and reports LastInsertId of the final batch.
+func BulkInsert[T any](ctx context.Context, tx *sqlx.Tx, table string, rows []T, opts ...BulkInsertOptions) (sql.Result, error) {
+	if len(rows) == 0 {
+		return bulkResult{}, nil
+	}
+	if err := validateIdentifier("table", table); err != nil {
+		return nil, err
+	}
+
+	columns, err := structColumns(reflect.TypeOf(rows[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	var configured int
+	if len(opts) > 0 {
+		configured = opts[0].BatchSize
+	}
+	batchSize := bulkBatchSize(configured, len(columns), tx.DriverName())
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (:%s)",
+		table, strings.Join(columns, ", "), strings.Join(columns, ", :"))
+
+	var total bulkResult
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		res, err := tx.NamedExecContext(ctx, query, rows[start:end])
+		if err != nil {
+			return total, fmt.Errorf("failed to bulk insert rows %d-%d into %s: %w", start, end-1, table, err)
+		}
+
+		if n, err := res.RowsAffected(); err == nil {
+			total.rowsAffected += n
+		}
+		if id, err := res.LastInsertId(); err == nil {
+			total.lastInsertID = id
+		}
+	}
+
+	return total, nil
+}
+
+// BulkUpdateOption configures BulkUpdate
+type BulkUpdateOption struct {
+	// BatchSize is the maximum number of rows per PostgreSQL UPDATE statement (default
+	// 1000). It is lowered if a batch would exceed PostgreSQL's bind parameter limit.
+	BatchSize int
+}
+
+// BulkUpdate updates rows of table by key, setting every other column mapped by the
+// struct's db tags. keyColumns names the columns matched in the WHERE clause. On
+// PostgreSQL rows are sent in batches as UPDATE ... FROM (VALUES ...) AS v, with the
+// values cast to the column types read from pg_attribute; other drivers run one
+// prepared UPDATE per row. It returns the total number of rows affected.
+func BulkUpdate[T any](ctx context.Context, tx *sqlx.Tx, table string, rows []T, keyColumns []string, opts ...BulkUpdateOption) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if err := validateIdentifier("table", table); err != nil {
+		return 0, err
+	}
+	if len(keyColumns) == 0 {
+		return 0, fmt.Errorf("bulk update of %s needs at least one key column", table)
+	}
+
+	columns, err := structColumns(reflect.TypeOf(rows[0]))
+	if err != nil {
+		return 0, err
+	}
+	var setColumns []string
+	for _, column := range columns {
+		if !slices.Contains(keyColumns, column) {
+			setColumns = append(setColumns, column)
+		}
+	}
+	for _, key := range keyColumns {
+		if !slices.Contains(columns, key) {
+			return 0, fmt.Errorf("key column %q is not mapped by %T", key, rows[0])
+		}
+	}
+	if len(setColumns) == 0 {
+		return 0, fmt.Errorf("bulk update of %s has no columns to set", table)
+	}
+
+	if isPostgres(tx.DriverName()) {
+		var configured int
+		if len(opts) > 0 {
+			configured = opts[0].BatchSize
+		}
+		batchSize := bulkBatchSize(configured, len(columns), tx.DriverName())
+		return bulkUpdatePostgres(ctx, tx, table, rows, columns, keyColumns, setColumns, batchSize)
+	}
+	return bulkUpdateRows(ctx, tx, table, rows, keyColumns, setColumns)
+}
+
+// bulkUpdatePostgres runs UPDATE ... FROM (VALUES ...) statements of at most batchSize rows
+func bulkUpdatePostgres[T any](ctx context.Context, tx *sqlx.Tx, table string, rows []T, columns, keyColumns, setColumns []string, batchSize int) (int64, error) {
+	types, err := pgColumnTypes(ctx, tx, table)
+	if err != nil {
+		return 0, err
+	}
+	for _, column := range columns {
+		if types[column] == "" {
+			return 0, fmt.Errorf("column %q not found in %s", column, table)
+		}
+	}
+
+	sets := make([]string, len(setColumns))
+	for i, column := range setColumns {
+		sets[i] = fmt.Sprintf("%s = v.%s", column, column)
+	}
+	matches := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		matches[i] = fmt.Sprintf("t.%s = v.%s", column, column)
+	}
+	rowTemplate := "(:" + strings.Join(columns, ", :") + ")"
+
+	var total int64
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		values := make([]string, 0, end-start)
+		var args []any
+		for _, row := range rows[start:end] {
+			_, rowArgs, err := sqlx.Named(rowTemplate, row)
+			if err != nil {
+				return total, fmt.Errorf("failed to bind row for %s: %w", table, err)
+			}
+			placeholders := make([]string, len(columns))
+			for i, column := range columns {
+				placeholders[i] = fmt.Sprintf("$%d::%s", len(args)+i+1, types[column])
+			}
+			values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, rowArgs...)
+		}
+
+		query := fmt.Sprintf("UPDATE %s AS t SET %s FROM (VALUES %s) AS v (%s) WHERE %s",
+			table, strings.Join(sets, ", "), strings.Join(values, ", "),
+			strings.Join(columns, ", "), strings.Join(matches, " AND "))
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, fmt.Errorf("failed to bulk update rows %d-%d in %s: %w", start, end-1, table, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// bulkUpdateRows runs one prepared UPDATE per row
+func bulkUpdateRows[T any](ctx context.Context, tx *sqlx.Tx, table string, rows []T, keyColumns, setColumns []string) (int64, error) {
+	sets := make([]string, len(setColumns))
+	for i, column := range setColumns {
+		sets[i] = fmt.Sprintf("%s = :%s", column, column)
+	}
+	matches := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		matches[i] = fmt.Sprintf("%s = :%s", column, column)
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), strings.Join(matches, " AND "))
+
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare %q: %w", query, err)
+	}
+	defer stmt.Close()
+
+	var total int64
+	for i, row := range rows {
+		res, err := stmt.ExecContext(ctx, row)
+		if err != nil {
+			return total, fmt.Errorf("failed to update row %d in %s: %w", i, table, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// pgColumnTypes returns the SQL type of every column of table, keyed by column name
+func pgColumnTypes(ctx context.Context, tx *sqlx.Tx, table string) (map[string]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT attname, format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped",
+		table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column types of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, typ string
+		if err := rows.Scan(&name, &typ); err != nil {
+			return nil, fmt.Errorf("failed to scan column type of %s: %w", table, err)
+		}
+		types[name] = typ
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read column types of %s: %w", table, err)
+	}
+	return types, nil
+}
@@ -0,0 +1,273 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type bulkUser struct {
+	ID       int    `db:"id"`
+	Name     string `db:"name"`
+	Internal string `db:"-"`
+}
+
+// wideRow has 70 columns, so 1000 rows exceed PostgreSQL's 65535 bind parameters
+type wideRow struct {
+	C00, C01, C02, C03, C04, C05, C06, C07, C08, C09, C10, C11, C12, C13,
+	C14, C15, C16, C17, C18, C19, C20, C21, C22, C23, C24, C25, C26, C27,
+	C28, C29, C30, C31, C32, C33, C34, C35, C36, C37, C38, C39, C40, C41,
+	C42, C43, C44, C45, C46, C47, C48, C49, C50, C51, C52, C53, C54, C55,
+	C56, C57, C58, C59, C60, C61, C62, C63, C64, C65, C66, C67, C68, C69 int
+}
+
+func TestBulkInsert_WideRowsStayWithinParameterLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	// 65535 / 70 columns = 936 rows, the first batch ending at parameter 936*70 = 65520
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("$65520)") + "$").WillReturnResult(sqlmock.NewResult(0, 936))
+	mock.ExpectExec(regexp.QuoteMeta("$4480)") + "$").WillReturnResult(sqlmock.NewResult(0, 64))
+	mock.ExpectCommit()
+
+	rows := make([]wideRow, 1000)
+	ctx := context.Background()
+	affected, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		res, err := BulkInsert(ctx, tx, "wide", rows)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if affected != 1000 {
+		t.Errorf("expected 1000 rows affected, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkInsert_Batches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (id, name) VALUES ($1, $2),($3, $4)")).
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (id, name) VALUES ($1, $2)")).
+		WithArgs(3, "c").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rows := []bulkUser{{1, "a", "x"}, {2, "b", "x"}, {3, "c", "x"}}
+	ctx := context.Background()
+	affected, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		res, err := BulkInsert(ctx, tx, "users", rows, BulkInsertOptions{BatchSize: 2})
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("expected 3 rows affected, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkInsert_MySQLPlaceholders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (id, name) VALUES (?, ?)")).
+		WithArgs(1, "a").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	tx, err := sqlxDB.Beginx()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	if _, err := BulkInsert(context.Background(), tx, "users", []bulkUser{{ID: 1, Name: "a"}}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkInsert_InvalidTable(t *testing.T) {
+	_, err := BulkInsert(context.Background(), nil, "users; DROP TABLE users", []bulkUser{{ID: 1}})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestBulkUpdate_PostgresValues(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT attname, format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = $1::regclass AND attnum > 0 AND NOT attisdropped").
+		WithArgs("users").
+		WillReturnRows(sqlmock.NewRows([]string{"attname", "format_type"}).AddRow("id", "integer").AddRow("name", "text"))
+	mock.ExpectExec("UPDATE users AS t SET name = v.name FROM (VALUES ($1::integer, $2::text), ($3::integer, $4::text)) AS v (id, name) WHERE t.id = v.id").
+		WithArgs(1, "a", 2, "b").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE users AS t SET name = v.name FROM (VALUES ($1::integer, $2::text)) AS v (id, name) WHERE t.id = v.id").
+		WithArgs(3, "c").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rows := []bulkUser{{1, "a", "x"}, {2, "b", "x"}, {3, "c", "x"}}
+	ctx := context.Background()
+	affected, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		return BulkUpdate(ctx, tx, "users", rows, []string{"id"}, BulkUpdateOption{BatchSize: 2})
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if affected != 3 {
+		t.Errorf("expected 3 rows affected, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkUpdate_WideRowsStayWithinParameterLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	types := sqlmock.NewRows([]string{"attname", "format_type"})
+	for i := range 70 {
+		types.AddRow(fmt.Sprintf("c%02d", i), "integer")
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT attname").WillReturnRows(types)
+	mock.ExpectExec(regexp.QuoteMeta("$65520::integer)) AS v")).WillReturnResult(sqlmock.NewResult(0, 936))
+	mock.ExpectExec(regexp.QuoteMeta("$4480::integer)) AS v")).WillReturnResult(sqlmock.NewResult(0, 64))
+	mock.ExpectCommit()
+
+	rows := make([]wideRow, 1000)
+	ctx := context.Background()
+	affected, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		return BulkUpdate(ctx, tx, "wide", rows, []string{"c00"})
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if affected != 1000 {
+		t.Errorf("expected 1000 rows affected, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkUpdate_MySQLPerRow(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare("UPDATE users SET name = ? WHERE id = ?")
+	prep.ExpectExec().WithArgs("a", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WithArgs("b", 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	rows := []bulkUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	ctx := context.Background()
+	affected, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		return BulkUpdate(ctx, tx, "users", rows, []string{"id"})
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("expected 1 row affected, got %d", affected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestBulkUpdate_UnknownKeyColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int64, error) {
+		return BulkUpdate(ctx, tx, "users", []bulkUser{{ID: 1}}, []string{"uuid"})
+	})
+
+	if err == nil {
+		t.Error("expected an error for an unmapped key column")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
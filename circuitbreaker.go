@@ -0,0 +1,89 @@
+package sqlxtx
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when WithCircuitBreaker refuses to start a transaction
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker decides whether transactions may be started. Allow is checked
+// before BEGIN; RecordSuccess is called after a commit and RecordFailure after a
+// failed begin or a rollback.
+type CircuitBreaker interface {
+	Allow() bool
+	RecordSuccess()
+	RecordFailure()
+}
+
+// WithCircuitBreaker stops transactions from reaching the database while cb is open,
+// failing them with ErrCircuitOpen instead. Rejected attempts are not reported to observers.
+func WithCircuitBreaker(cb CircuitBreaker) ConfigOption {
+	return func(c *Config) {
+		c.CircuitBreaker = cb
+	}
+}
+
+// recordBreaker reports a transaction outcome to the configured circuit breaker, if any
+func (c *Config) recordBreaker(success bool) {
+	switch {
+	case c.CircuitBreaker == nil:
+	case success:
+		c.CircuitBreaker.RecordSuccess()
+	default:
+		c.CircuitBreaker.RecordFailure()
+	}
+}
+
+// simpleCircuitBreaker opens after threshold consecutive failures and lets a single
+// trial through once halfOpenAfter has elapsed
+type simpleCircuitBreaker struct {
+	mu            sync.Mutex
+	threshold     int
+	halfOpenAfter time.Duration
+	failures      int
+	openedAt      time.Time
+	trialRunning  bool
+}
+
+// NewSimpleCircuitBreaker returns a CircuitBreaker that opens after threshold
+// consecutive failures. Once halfOpenAfter has passed, one transaction is allowed
+// through: success closes the breaker, failure keeps it open for another period.
+func NewSimpleCircuitBreaker(threshold int, halfOpenAfter time.Duration) CircuitBreaker {
+	return &simpleCircuitBreaker{threshold: threshold, halfOpenAfter: halfOpenAfter}
+}
+
+func (cb *simpleCircuitBreaker) Allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.threshold {
+		return true
+	}
+	if cb.trialRunning || time.Since(cb.openedAt) < cb.halfOpenAfter {
+		return false
+	}
+	cb.trialRunning = true
+	return true
+}
+
+func (cb *simpleCircuitBreaker) RecordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.trialRunning = false
+}
+
+func (cb *simpleCircuitBreaker) RecordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.openedAt = time.Now()
+	}
+	cb.trialRunning = false
+}
@@ -0,0 +1,115 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_CircuitBreakerOpensAfterFailures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	beginErr := errors.New("connection refused")
+	mock.ExpectBegin().WillReturnError(beginErr)
+	mock.ExpectBegin().WillReturnError(beginErr)
+
+	cb := NewSimpleCircuitBreaker(2, time.Hour)
+	calls := 0
+	txFunc := func(tx *sqlx.Tx) (any, error) {
+		calls++
+		return nil, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := ExecuteContext(context.Background(), sqlxDB, txFunc, WithCircuitBreaker(cb)); !IsBeginError(err) {
+			t.Errorf("attempt %d: expected begin error, got %v", i+1, err)
+		}
+	}
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, txFunc, WithCircuitBreaker(cb))
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected the transaction function not to run, got %d calls", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSimpleCircuitBreaker_HalfOpen(t *testing.T) {
+	cb := NewSimpleCircuitBreaker(1, 10*time.Millisecond)
+
+	cb.RecordFailure()
+	if cb.Allow() {
+		t.Fatal("expected breaker to be open after reaching the threshold")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !cb.Allow() {
+		t.Fatal("expected a trial to be allowed once half-open")
+	}
+	if cb.Allow() {
+		t.Error("expected only one trial while half-open")
+	}
+
+	cb.RecordFailure()
+	if cb.Allow() {
+		t.Error("expected breaker to reopen after a failed trial")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !cb.Allow() {
+		t.Fatal("expected a second trial to be allowed")
+	}
+	cb.RecordSuccess()
+	if !cb.Allow() || !cb.Allow() {
+		t.Error("expected breaker to close after a successful trial")
+	}
+}
+
+func TestExecuteContext_CircuitOpenSkipsObservers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	cb := NewSimpleCircuitBreaker(1, time.Hour)
+	cb.RecordFailure()
+
+	observed := 0
+	observer := func(ctx context.Context, info TxInfo) (context.Context, func(committed bool, err error)) {
+		observed++
+		return ctx, nil
+	}
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithCircuitBreaker(cb), WithObserver(observer))
+
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if observed != 0 {
+		t.Errorf("expected no observer call while the circuit is open, got %d", observed)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
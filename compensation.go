@@ -0,0 +1,70 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CompensatingAction undoes work done outside the database, e.g. by a SAGA step
+type CompensatingAction = func() error
+
+// CompensatingTxFunc is a TxFunc that also returns the action to run if the
+// transaction is rolled back. A nil action means nothing needs compensating.
+type CompensatingTxFunc[T any] func(tx *sqlx.Tx) (T, CompensatingAction, error)
+
+// WithSyncCompensation makes ExecuteWithCompensation run compensating actions before
+// it returns and report their errors, instead of running them in a new goroutine.
+// The other Execute functions reject it with ErrInvalidOption.
+func WithSyncCompensation() ConfigOption {
+	return func(c *Config) {
+		c.SyncCompensation = true
+	}
+}
+
+// ExecuteWithCompensation runs fn within a transaction and calls the compensating
+// action it returned whenever that attempt is rolled back, including after a failed
+// commit. Actions run in a new goroutine and their errors are discarded unless
+// WithSyncCompensation is set.
+func ExecuteWithCompensation[T any](ctx context.Context, db Executer, fn CompensatingTxFunc[T], options ...ConfigOption) (T, error) {
+	var action CompensatingAction
+	var compErr error
+
+	result, err := execute(ctx, db, entryCompensation, func(config *Config) (ContextTxFunc[T], error) {
+		syncMode := config.SyncCompensation
+		config.OnRollback = append(config.OnRollback, func(error) {
+			run := action
+			action = nil
+			if run == nil {
+				return
+			}
+			if !syncMode {
+				go func() { _ = callHook(func() { _ = run() }) }()
+				return
+			}
+			compErr = errors.Join(compErr, runCompensation(run))
+		})
+		return func(_ context.Context, tx *sqlx.Tx) (T, error) {
+			var res T
+			var err error
+			res, action, err = fn(tx)
+			return res, err
+		}, nil
+	}, options)
+
+	if compErr != nil {
+		err = fmt.Errorf("%w (compensation error: %v)", err, compErr)
+	}
+	return result, err
+}
+
+// runCompensation calls action, converting a panic into an error
+func runCompensation(action CompensatingAction) error {
+	var err error
+	if hookErr := callHook(func() { err = action() }); hookErr != nil {
+		return hookErr
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteWithCompensation_SyncRunsOnRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	compErr := errors.New("refund failed")
+	compensated := false
+	_, err = ExecuteWithCompensation(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, CompensatingAction, error) {
+		return 0, func() error {
+			compensated = true
+			return compErr
+		}, txErr
+	}, WithSyncCompensation())
+
+	if !compensated {
+		t.Error("expected compensating action to run")
+	}
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), compErr.Error()) {
+		t.Errorf("expected compensation error in %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithCompensation_AsyncRunsOnCommitFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+
+	done := make(chan struct{})
+	_, err = ExecuteWithCompensation(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, CompensatingAction, error) {
+		return 1, func() error {
+			close(done)
+			return nil
+		}, nil
+	})
+
+	if !IsCommitError(err) {
+		t.Errorf("expected commit error, got %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("expected compensating action to run asynchronously")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithCompensation_NotRunOnCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	compensated := false
+	result, err := ExecuteWithCompensation(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, CompensatingAction, error) {
+		return 42, func() error {
+			compensated = true
+			return nil
+		}, nil
+	}, WithSyncCompensation())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if result != 42 {
+		t.Errorf("expected result to be 42, got %v", result)
+	}
+	if compensated {
+		t.Error("expected compensating action not to run after commit")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithCompensation_AppliesOptionsOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	applied := 0
+	countOption := func(c *Config) { applied++ }
+	_, err = ExecuteWithCompensation(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, CompensatingAction, error) {
+		return 1, nil, nil
+	}, countOption, WithSyncCompensation())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected options to be applied once, got %d", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithSyncCompensation_RejectedOutsideExecuteWithCompensation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		t.Error("expected the transaction function not to run")
+		return 0, nil
+	}, WithSyncCompensation())
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
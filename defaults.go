@@ -0,0 +1,57 @@
+package sqlxtx
+
+import "sync"
+
+var (
+	defaultOptionsMu sync.RWMutex
+	defaultOptions   []ConfigOption
+)
+
+// SetDefaultOptions replaces the package-wide default options. Defaults are applied
+// before the options passed to each call, so call-site options win.
+func SetDefaultOptions(options ...ConfigOption) {
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	defaultOptions = append([]ConfigOption(nil), options...)
+}
+
+// GetDefaultOptions returns a copy of the package-wide default options
+func GetDefaultOptions() []ConfigOption {
+	defaultOptionsMu.RLock()
+	defer defaultOptionsMu.RUnlock()
+	return append([]ConfigOption(nil), defaultOptions...)
+}
+
+// ResetDefaultOptions clears the package-wide default options, e.g. in test teardown
+func ResetDefaultOptions() {
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	defaultOptions = nil
+}
+
+// MergeOptions returns a new slice holding base followed by override. Options are
+// applied left to right and each one overwrites what it sets, so override wins over
+// base: with WithReadCommitted in base and WithSerializable in override the
+// transaction is serializable. Options that accumulate, such as hooks, keep both.
+func MergeOptions(base, override []ConfigOption) []ConfigOption {
+	merged := make([]ConfigOption, 0, len(base)+len(override))
+	merged = append(merged, base...)
+	return append(merged, override...)
+}
+
+// OverrideOptions is MergeOptions with the overrides passed variadically
+func OverrideOptions(base []ConfigOption, overrides ...ConfigOption) []ConfigOption {
+	return MergeOptions(base, overrides)
+}
+
+// newConfig builds a Config from the package defaults followed by options
+func newConfig(options []ConfigOption) *Config {
+	config := &Config{Version: ConfigVersion}
+	for _, option := range GetDefaultOptions() {
+		option(config)
+	}
+	for _, option := range options {
+		option(config)
+	}
+	return config
+}
@@ -0,0 +1,80 @@
+package sqlxtx
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecute_AppliesDefaultOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	SetDefaultOptions(WithDeallocateAll())
+	defer ResetDefaultOptions()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DEALLOCATE ALL").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = Execute(sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDefaultOptions_CallSiteOverrides(t *testing.T) {
+	SetDefaultOptions(WithSerializable())
+	defer ResetDefaultOptions()
+
+	if got := len(GetDefaultOptions()); got != 1 {
+		t.Fatalf("expected 1 default option, got %d", got)
+	}
+
+	config := newConfig([]ConfigOption{WithReadCommitted()})
+	if config.TxOptions.Isolation.String() != "Read Committed" {
+		t.Errorf("expected call-site isolation level to win, got %v", config.TxOptions.Isolation)
+	}
+
+	ResetDefaultOptions()
+	if got := len(GetDefaultOptions()); got != 0 {
+		t.Errorf("expected no default options after reset, got %d", got)
+	}
+}
+
+func TestOverrideOptions_LastIsolationWins(t *testing.T) {
+	base := []ConfigOption{WithReadCommitted(), WithTimeout(time.Second)}
+	merged := OverrideOptions(base, WithSerializable())
+
+	if len(base) != 2 {
+		t.Errorf("expected base to be unchanged, got %d options", len(base))
+	}
+
+	config := newConfig(merged)
+	if config.TxOptions.Isolation != sql.LevelSerializable {
+		t.Errorf("expected serializable isolation, got %v", config.TxOptions.Isolation)
+	}
+	if config.Timeout != time.Second {
+		t.Errorf("expected base timeout to be kept, got %s", config.Timeout)
+	}
+
+	config = newConfig(MergeOptions([]ConfigOption{WithSerializable()}, []ConfigOption{WithReadCommitted()}))
+	if config.TxOptions.Isolation != sql.LevelReadCommitted {
+		t.Errorf("expected read committed isolation, got %v", config.TxOptions.Isolation)
+	}
+}
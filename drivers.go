@@ -0,0 +1,132 @@
+package sqlxtx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDriverNotSupported is returned when a driver-specific option is used with a
+// database driver it does not support
+var ErrDriverNotSupported = errors.New("option not supported by this database driver")
+
+// ErrFeatureNotSupported is returned when an option needs a server feature that the
+// database, or its version, does not provide
+var ErrFeatureNotSupported = errors.New("feature not supported by this database")
+
+// Driver names as registered with database/sql by the common drivers
+var (
+	postgresDrivers = []string{"postgres", "pgx", "pq-timeouts", "cloudsqlpostgres", "nrpostgres", "cockroach"}
+	mysqlDrivers    = []string{"mysql", "nrmysql"}
+	sqliteDrivers   = []string{"sqlite3", "sqlite", "nrsqlite3"}
+)
+
+// isPostgres reports whether driverName belongs to a PostgreSQL driver
+func isPostgres(driverName string) bool {
+	return containsDriver(postgresDrivers, driverName)
+}
+
+// isMySQL reports whether driverName belongs to a MySQL driver
+func isMySQL(driverName string) bool {
+	return containsDriver(mysqlDrivers, driverName)
+}
+
+// isSQLite reports whether driverName belongs to a SQLite driver
+func isSQLite(driverName string) bool {
+	return containsDriver(sqliteDrivers, driverName)
+}
+
+func containsDriver(drivers []string, driverName string) bool {
+	for _, d := range drivers {
+		if d == driverName {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDriverSupport rejects options meant for a different database. Only drivers
+// known to be incompatible are rejected, so unrecognized drivers are let through.
+func checkDriverSupport(driverName string, config *Config) error {
+	if isMySQL(driverName) || isSQLite(driverName) {
+		if config.DeallocateAll {
+			return unsupportedOption("DEALLOCATE ALL", driverName)
+		}
+		if len(config.DeallocateNames) > 0 || len(config.DeallocatePatterns) > 0 {
+			return unsupportedOption("DEALLOCATE", driverName)
+		}
+		if config.StatementTimeout > 0 || config.LockTimeout > 0 {
+			return unsupportedOption("statement and lock timeouts", driverName)
+		}
+		if config.IdleInTransactionTimeout > 0 {
+			return fmt.Errorf("%w: %w", ErrFeatureNotSupported,
+				unsupportedOption("idle_in_transaction_session_timeout", driverName))
+		}
+		if config.LogParameters {
+			return unsupportedOption("log_parameter_max_length", driverName)
+		}
+		if len(config.RequiredExtensions) > 0 {
+			return unsupportedOption("pg_extension checks", driverName)
+		}
+		if len(config.AdvisoryLocks) > 0 || len(config.AdvisoryTryLocks) > 0 {
+			return unsupportedOption("advisory locks", driverName)
+		}
+		if len(config.SearchPath) > 0 {
+			return unsupportedOption("search_path", driverName)
+		}
+		if config.Role != "" {
+			return unsupportedOption("SET ROLE", driverName)
+		}
+		if config.WorkMem != "" {
+			return unsupportedOption("work_mem", driverName)
+		}
+		if config.SynchronousCommit != "" {
+			return unsupportedOption("synchronous_commit", driverName)
+		}
+		if config.ApplicationName != "" {
+			return unsupportedOption("application_name", driverName)
+		}
+		if len(config.SessionVariables) > 0 {
+			return unsupportedOption("session variables", driverName)
+		}
+		if len(config.Notifications) > 0 {
+			return unsupportedOption("NOTIFY", driverName)
+		}
+		if len(config.RefreshViews) > 0 {
+			return unsupportedOption("REFRESH MATERIALIZED VIEW", driverName)
+		}
+		if config.ExplainLogger != nil {
+			return unsupportedOption("EXPLAIN (ANALYZE, BUFFERS)", driverName)
+		}
+	}
+
+	if isPostgres(driverName) || isSQLite(driverName) {
+		if len(config.MySQLDeallocate) > 0 {
+			return unsupportedOption("DEALLOCATE PREPARE", driverName)
+		}
+	}
+
+	if isPostgres(driverName) || isSQLite(driverName) {
+		if config.MySQLCharset != "" {
+			return unsupportedOption("SET NAMES", driverName)
+		}
+		if config.MySQLTimezone != "" {
+			return unsupportedOption("SET time_zone", driverName)
+		}
+	}
+
+	if isPostgres(driverName) || isMySQL(driverName) {
+		if config.SQLiteBeginMode != "" {
+			return unsupportedOption("BEGIN "+config.SQLiteBeginMode, driverName)
+		}
+		if config.SQLiteBusyTimeout > 0 {
+			return unsupportedOption("busy_timeout", driverName)
+		}
+	}
+
+	return nil
+}
+
+// unsupportedOption formats an error wrapping ErrDriverNotSupported
+func unsupportedOption(option, driverName string) error {
+	return fmt.Errorf("%w: %s is not supported by driver %q", ErrDriverNotSupported, option, driverName)
+}
@@ -0,0 +1,89 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestCheckDriverSupport_RejectsForeignOptions(t *testing.T) {
+	cases := []struct {
+		name   string
+		driver string
+		option ConfigOption
+	}{
+		{"deallocate all", "mysql", WithDeallocateAll()},
+		{"deallocate statement", "sqlite3", WithDeallocateStatement("stmt_1")},
+		{"statement timeout", "mysql", WithStatementTimeout(time.Second)},
+		{"lock wait timeout", "sqlite3", WithPGLockWaitTimeout(time.Second)},
+		{"idle in transaction timeout", "mysql", WithIdleInTransactionTimeout(time.Second)},
+		{"advisory lock", "mysql", WithAdvisoryLock(1)},
+		{"advisory try lock", "sqlite3", WithAdvisoryTryLock(1)},
+		{"search path", "mysql", WithSearchPath("tenant_a")},
+		{"extension required", "sqlite3", WithPGExtensionRequired("pgcrypto")},
+		{"log parameters", "mysql", WithPGLogParameters(-1)},
+		{"session variables", "sqlite3", WithSessionVariables(map[string]string{"app.user_id": "1"})},
+		{"row level security", "mysql", WithPGCurrentUser(1)},
+		{"application name", "mysql", WithApplicationName("worker")},
+		{"correlation id", "sqlite3", WithCorrelationID("req-1")},
+		{"synchronous commit", "mysql", WithSynchronousCommit("off")},
+		{"work mem", "sqlite3", WithWorkMem("64MB")},
+		{"role", "mysql", WithPGRole("app_user")},
+		{"notify", "mysql", WithNotifyOnCommit("events", "x")},
+		{"materialized view", "sqlite3", WithRefreshMaterializedView("daily_totals")},
+		{"mysql deallocate", "postgres", WithMySQLDeallocate("stmt_1")},
+		{"mysql charset", "postgres", WithMySQLCharset("utf8mb4")},
+		{"mysql timezone", "sqlite3", WithMySQLTimezone("+00:00")},
+		{"sqlite immediate", "postgres", WithSQLiteImmediate()},
+		{"sqlite busy timeout", "mysql", WithSQLiteBusyTimeout(time.Second)},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		c.option(config)
+
+		if err := checkDriverSupport(c.driver, config); !errors.Is(err, ErrDriverNotSupported) {
+			t.Errorf("%s on %s: expected ErrDriverNotSupported, got %v", c.name, c.driver, err)
+		}
+	}
+}
+
+func TestExecuteContext_DriverCheckedAfterBegin(t *testing.T) {
+	primary, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer replica.Close()
+
+	// The handle reports the primary's driver; the read-only transaction runs on a MySQL replica
+	db := NewPrimaryReplicaDB(sqlx.NewDb(primary, "postgres"), sqlx.NewDb(replica, "mysql"))
+
+	replicaMock.ExpectBegin()
+	replicaMock.ExpectRollback()
+
+	called := false
+	_, err = ExecuteContext(context.Background(), db, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithReadOnly(), WithSearchPath("tenant_a"))
+
+	if !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrDriverNotSupported, got %v", err)
+	}
+	if called {
+		t.Error("expected the transaction function not to be called")
+	}
+
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
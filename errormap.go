@@ -0,0 +1,61 @@
+package sqlxtx
+
+import (
+	"fmt"
+)
+
+// ErrorMapper translates errors returned by the transaction function, e.g. driver
+// errors into domain errors
+type ErrorMapper interface {
+	Map(err error) error
+}
+
+// WithErrorMapper passes every error returned by the transaction function through m
+// before the transaction is rolled back
+func WithErrorMapper(m ErrorMapper) ConfigOption {
+	return func(c *Config) {
+		c.ErrorMapper = m
+	}
+}
+
+// PGErrorMapper maps PostgreSQL errors to domain errors by SQLSTATE code
+type PGErrorMapper struct {
+	rules map[string]error
+}
+
+// NewPGErrorMapper creates a PGErrorMapper from SQLSTATE codes to domain errors,
+// e.g. {SQLStateUniqueViolation: ErrDuplicateUser}
+func NewPGErrorMapper(rules map[string]error) *PGErrorMapper {
+	return &PGErrorMapper{rules: rules}
+}
+
+// Map returns an error matching both the domain error and err, or err unchanged if
+// no rule matches its SQLSTATE
+func (m *PGErrorMapper) Map(err error) error {
+	if domainErr, ok := m.rules[sqlState(err)]; ok {
+		return fmt.Errorf("%w: %w", domainErr, err)
+	}
+	return err
+}
+
+// MySQLErrorMapper maps MySQL errors to domain errors by error number
+type MySQLErrorMapper struct {
+	rules map[uint16]error
+}
+
+// NewMySQLErrorMapper creates a MySQLErrorMapper from MySQL error numbers to domain
+// errors, e.g. {MySQLErrDupEntry: ErrDuplicateUser}
+func NewMySQLErrorMapper(rules map[uint16]error) *MySQLErrorMapper {
+	return &MySQLErrorMapper{rules: rules}
+}
+
+// Map returns an error matching both the domain error and err, or err unchanged if
+// no rule matches its error number
+func (m *MySQLErrorMapper) Map(err error) error {
+	if number, ok := mysqlErrorNumber(err); ok {
+		if domainErr, ok := m.rules[number]; ok {
+			return fmt.Errorf("%w: %w", domainErr, err)
+		}
+	}
+	return err
+}
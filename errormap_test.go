@@ -0,0 +1,68 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var errDuplicateUser = errors.New("user already exists")
+
+func TestExecuteContext_ErrorMapperRunsBeforeRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	pqErr := &pq.Error{Code: "23505"}
+	var rolledBackWith error
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, pqErr
+	},
+		WithErrorMapper(NewPGErrorMapper(map[string]error{SQLStateUniqueViolation: errDuplicateUser})),
+		WithOnRollback(func(err error) { rolledBackWith = err }),
+	)
+
+	if !errors.Is(err, errDuplicateUser) {
+		t.Errorf("expected mapped error, got %v", err)
+	}
+	if !IsUniqueViolation(err) {
+		t.Errorf("expected the driver error to stay in the chain, got %v", err)
+	}
+	if !errors.Is(rolledBackWith, errDuplicateUser) {
+		t.Errorf("expected rollback hook to receive mapped error, got %v", rolledBackWith)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMySQLErrorMapper(t *testing.T) {
+	mapper := NewMySQLErrorMapper(map[uint16]error{MySQLErrDupEntry: errDuplicateUser})
+
+	if err := mapper.Map(&mysql.MySQLError{Number: 1062}); !errors.Is(err, errDuplicateUser) {
+		t.Errorf("expected mapped error, got %v", err)
+	}
+
+	other := &mysql.MySQLError{Number: 1213}
+	if err := mapper.Map(other); err != other {
+		t.Errorf("expected unmatched error to pass through, got %v", err)
+	}
+
+	plain := errors.New("plain")
+	if err := mapper.Map(plain); err != plain {
+		t.Errorf("expected non-MySQL error to pass through, got %v", err)
+	}
+}
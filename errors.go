@@ -0,0 +1,145 @@
+package sqlxtx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLockNotAcquired is returned when WithAdvisoryTryLock could not take its lock
+var ErrLockNotAcquired = errors.New("advisory lock not acquired")
+
+// ErrReadOnlyTransaction is returned when a locking helper is used in a read-only transaction
+var ErrReadOnlyTransaction = errors.New("transaction is read-only")
+
+// ErrNotFound is returned by SoftDelete and SoftDeleteBatch when no row was updated
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict is returned by OptimisticUpdate when the row's version no longer
+// matches, i.e. it was changed or deleted since it was read
+var ErrVersionConflict = errors.New("optimistic lock version conflict")
+
+// ErrExtensionNotInstalled is returned when an extension required with
+// WithPGExtensionRequired is not installed in the database
+var ErrExtensionNotInstalled = errors.New("postgres extension not installed")
+
+// BeginError is returned when the transaction could not be started
+type BeginError struct {
+	Err error
+}
+
+func (e BeginError) Error() string {
+	return fmt.Sprintf("failed to begin transaction: %v", e.Err)
+}
+
+func (e BeginError) Unwrap() error {
+	return e.Err
+}
+
+// CommitError is returned when the commit failed. Err holds the commit failure. Cause
+// is nil unless WithOnError chose to commit despite an error from the transaction
+// function, in which case it holds that error.
+type CommitError struct {
+	Err   error
+	Cause error
+}
+
+func (e CommitError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("failed to commit transaction: %v (original error: %v)", e.Err, e.Cause)
+	}
+	return fmt.Sprintf("failed to commit transaction: %v", e.Err)
+}
+
+// Unwrap returns the commit failure and, if set, Cause, so errors.Is matches either
+func (e CommitError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// RollbackError is returned when rolling back after a failure did not succeed.
+// Err holds the rollback failure and Cause the error that triggered the rollback.
+type RollbackError struct {
+	Err   error
+	Cause error
+}
+
+func (e RollbackError) Error() string {
+	return fmt.Sprintf("transaction rollback failed: %v (original error: %v)", e.Err, e.Cause)
+}
+
+// Unwrap returns the error that triggered the rollback and the rollback failure, so
+// errors.Is matches either, e.g. the original error as well as sql.ErrTxDone
+func (e RollbackError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Cause, e.Err}
+}
+
+// PostCommitError collects the failures of WithPostCommitAction actions. The
+// transaction itself was committed.
+type PostCommitError struct {
+	Errs []error
+}
+
+func (e PostCommitError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d post-commit action(s) failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns every action failure so errors.Is and errors.As match any of them
+func (e PostCommitError) Unwrap() []error {
+	return e.Errs
+}
+
+// GroupedError collects the failures of the commit hooks and post-commit actions of
+// a transaction that committed, so none of them is lost. It follows the Go 1.20
+// multi-error convention: errors.Is and errors.As match any collected error,
+// including a PostCommitError.
+type GroupedError struct {
+	Errs []error
+}
+
+func (e GroupedError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("transaction committed but %d hook(s) failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// Errors returns the collected errors in the order they occurred
+func (e GroupedError) Errors() []error {
+	return e.Errs
+}
+
+// Unwrap returns every collected error so errors.Is and errors.As match any of them
+func (e GroupedError) Unwrap() []error {
+	return e.Errs
+}
+
+// IsBeginError reports whether err was caused by a failure to begin the transaction
+func IsBeginError(err error) bool {
+	return errors.As(err, &BeginError{})
+}
+
+// IsCommitError reports whether err was caused by a failure to commit the transaction
+func IsCommitError(err error) bool {
+	return errors.As(err, &CommitError{})
+}
+
+// IsRollbackError reports whether err was caused by a failure to roll back the transaction
+func IsRollbackError(err error) bool {
+	return errors.As(err, &RollbackError{})
+}
+
+// IsPostCommitError reports whether err was caused by a failed post-commit action
+func IsPostCommitError(err error) bool {
+	return errors.As(err, &PostCommitError{})
+}
@@ -0,0 +1,110 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_BeginError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	beginErr := errors.New("connection refused")
+	mock.ExpectBegin().WillReturnError(beginErr)
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	})
+
+	if !IsBeginError(err) {
+		t.Errorf("expected begin error, got %v", err)
+	}
+	if !errors.Is(err, beginErr) {
+		t.Errorf("expected error to wrap driver error, got %v", err)
+	}
+}
+
+func TestExecuteContext_CommitError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(errors.New("commit failed"))
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	})
+
+	var commitErr CommitError
+	if !errors.As(err, &commitErr) {
+		t.Fatalf("expected commit error, got %v", err)
+	}
+	if IsBeginError(err) || IsRollbackError(err) {
+		t.Errorf("expected only a commit error, got %v", err)
+	}
+}
+
+func TestExecuteContext_RollbackError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	rollbackErr := errors.New("rollback failed")
+	mock.ExpectBegin()
+	mock.ExpectRollback().WillReturnError(rollbackErr)
+
+	txErr := errors.New("test error")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	})
+
+	var rbErr RollbackError
+	if !errors.As(err, &rbErr) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	if rbErr.Err != rollbackErr {
+		t.Errorf("expected rollback failure %v, got %v", rollbackErr, rbErr.Err)
+	}
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected error to wrap original error, got %v", err)
+	}
+}
+
+func TestRollbackError_UnwrapsBothErrors(t *testing.T) {
+	cause := errors.New("test error")
+	err := fmt.Errorf("wrapped: %w", RollbackError{Err: driver.ErrBadConn, Cause: cause})
+
+	if !errors.Is(err, cause) {
+		t.Errorf("expected errors.Is to match the cause, got %v", err)
+	}
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("expected errors.Is to match the rollback failure, got %v", err)
+	}
+	if errors.Is(RollbackError{Err: sql.ErrTxDone}, cause) {
+		t.Error("expected a RollbackError without cause not to match an unrelated error")
+	}
+	if !errors.Is(RollbackError{Err: sql.ErrTxDone}, sql.ErrTxDone) {
+		t.Error("expected a RollbackError without cause to match its rollback failure")
+	}
+}
@@ -0,0 +1,91 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithExplainAutoLog records the queries run through an InterceptedTx (see
+// ExecuteIntercepted) and, when the transaction took longer than threshold, replays
+// each one with EXPLAIN (ANALYZE, BUFFERS, FORMAT TEXT) and logs the plans to logger,
+// or to slog.Default() if logger is nil (PostgreSQL only). Plans are taken after the
+// fact, each in its own read-only transaction that is rolled back, so queries that
+// write fail to explain and are logged as warnings instead. Like the other
+// InterceptedTx options it is rejected by entry points other than ExecuteIntercepted.
+//
+// EXPLAIN ANALYZE executes every query a second time, so this is meant for tests and
+// should not be enabled in production code.
+func WithExplainAutoLog(logger *slog.Logger, threshold time.Duration) ConfigOption {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(c *Config) {
+		c.ExplainLogger = logger
+		c.ExplainThreshold = threshold
+	}
+}
+
+// recordedQuery is a query and its arguments as run through an InterceptedTx
+type recordedQuery struct {
+	query string
+	args  []any
+}
+
+// queryRecorder collects the queries of a transaction attempt. A nil recorder
+// records nothing.
+type queryRecorder struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+}
+
+func (r *queryRecorder) record(query string, args []any) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, recordedQuery{query: query, args: args})
+}
+
+// reset drops the queries of a previous attempt
+func (r *queryRecorder) reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = nil
+}
+
+// explainQueries logs the plan of every query
+func explainQueries(ctx context.Context, db Executer, logger *slog.Logger, elapsed time.Duration, queries []recordedQuery) {
+	for _, q := range queries {
+		plan, err := explainQuery(ctx, db, q)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to explain query of slow transaction",
+				"elapsed", elapsed, "query", q.query, "error", err)
+			continue
+		}
+		logger.InfoContext(ctx, "query plan of slow transaction",
+			"elapsed", elapsed, "query", q.query, "plan", plan)
+	}
+}
+
+// explainQuery runs EXPLAIN ANALYZE for q in a read-only transaction that is rolled back
+func explainQuery(ctx context.Context, db Executer, q recordedQuery) (string, error) {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return "", BeginError{Err: err}
+	}
+	defer tx.Rollback()
+
+	var lines []string
+	if err := tx.SelectContext(ctx, &lines, "EXPLAIN (ANALYZE, BUFFERS, FORMAT TEXT) "+q.query, q.args...); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,114 @@
+package sqlxtx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteIntercepted_ExplainAutoLog(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM users WHERE id = $1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a"))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("EXPLAIN (ANALYZE, BUFFERS, FORMAT TEXT) SELECT name FROM users WHERE id = $1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow("Index Scan using users_pkey on users"))
+	mock.ExpectRollback()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	ctx := context.Background()
+	_, err = ExecuteIntercepted(ctx, sqlxDB, func(tx *InterceptedTx) (string, error) {
+		var name string
+		err := tx.GetContext(ctx, &name, "SELECT name FROM users WHERE id = $1", 1)
+		return name, err
+	}, WithExplainAutoLog(logger, 0))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "Index Scan using users_pkey on users") {
+		t.Errorf("expected the plan to be logged, got %q", buf.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteIntercepted_ExplainAutoLogSkipsFastTransactions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	ctx := context.Background()
+	_, err = ExecuteIntercepted(ctx, sqlxDB, func(tx *InterceptedTx) (any, error) {
+		_, err := tx.ExecContext(ctx, "DELETE FROM sessions")
+		return nil, err
+	}, WithExplainAutoLog(logger, time.Minute))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be logged, got %q", buf.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithExplainAutoLog_NilLogger(t *testing.T) {
+	config := newConfig([]ConfigOption{WithExplainAutoLog(nil, time.Second)})
+	if config.ExplainLogger != slog.Default() {
+		t.Errorf("expected a nil logger to fall back to slog.Default()")
+	}
+}
+
+func TestExecuteContext_RejectsExplainAutoLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	_, err = ExecuteContext(context.Background(), sqlx.NewDb(db, "postgres"), func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithExplainAutoLog(nil, 0))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -4,4 +4,13 @@ go 1.24.3
 
 require github.com/jmoiron/sqlx v1.4.0
 
-require github.com/DATA-DOG/go-sqlmock v1.5.2 // indirect
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/go-sql-driver/mysql v1.10.1
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.12.3
+	github.com/mattn/go-sqlite3 v1.14.52
+	golang.org/x/time v0.12.0
+)
+
+require filippo.io/edwards25519 v1.2.0 // indirect
@@ -0,0 +1,60 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxGroup runs transactions against several databases, e.g. an application and an
+// audit database, as a best-effort unit. It is NOT a distributed transaction: members
+// commit one after another, and when one fails the members that already committed
+// are undone by running their compensating actions. A crash in between, or a failing
+// compensation, leaves the databases inconsistent. The zero value is ready to use.
+type TxGroup struct {
+	members []groupMember
+}
+
+type groupMember struct {
+	db         Executer
+	fn         func(tx *sqlx.Tx) error
+	compensate CompensatingAction
+}
+
+// Add appends a member that runs fn in its own transaction on db. compensate is
+// called if a later member fails after this one committed; it may be nil.
+func (g *TxGroup) Add(db Executer, fn func(tx *sqlx.Tx) error, compensate CompensatingAction) *TxGroup {
+	g.members = append(g.members, groupMember{db: db, fn: fn, compensate: compensate})
+	return g
+}
+
+// Execute runs the members in the order they were added, applying options to every
+// transaction. On the first failure it compensates the committed members in reverse
+// order and returns the failure, with any compensation errors appended.
+func (g *TxGroup) Execute(ctx context.Context, options ...ConfigOption) error {
+	for i, m := range g.members {
+		if err := ExecuteVoidContext(ctx, m.db, m.fn, options...); err != nil {
+			err = fmt.Errorf("transaction group member %d failed: %w", i, err)
+			if compErr := g.compensate(i); compErr != nil {
+				err = fmt.Errorf("%w (compensation error: %v)", err, compErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// compensate undoes the members before failed, latest first
+func (g *TxGroup) compensate(failed int) error {
+	var errs error
+	for i := failed - 1; i >= 0; i-- {
+		if action := g.members[i].compensate; action != nil {
+			if err := runCompensation(action); err != nil {
+				errs = errors.Join(errs, fmt.Errorf("member %d: %w", i, err))
+			}
+		}
+	}
+	return errs
+}
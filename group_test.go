@@ -0,0 +1,101 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTxGroup_CommitsEveryMember(t *testing.T) {
+	appDB, appMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer appDB.Close()
+	auditDB, auditMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer auditDB.Close()
+
+	appMock.ExpectBegin()
+	appMock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	appMock.ExpectCommit()
+	auditMock.ExpectBegin()
+	auditMock.ExpectExec("INSERT INTO audit_log").WillReturnResult(sqlmock.NewResult(1, 1))
+	auditMock.ExpectCommit()
+
+	var group TxGroup
+	err = group.
+		Add(sqlx.NewDb(appDB, "postgres"), func(tx *sqlx.Tx) error {
+			_, err := tx.Exec("INSERT INTO orders DEFAULT VALUES")
+			return err
+		}, nil).
+		Add(sqlx.NewDb(auditDB, "postgres"), func(tx *sqlx.Tx) error {
+			_, err := tx.Exec("INSERT INTO audit_log DEFAULT VALUES")
+			return err
+		}, nil).
+		Execute(context.Background())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := appMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if err := auditMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTxGroup_CompensatesCommittedMembers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	compErr := errors.New("compensation failed")
+	var undone []string
+	var group TxGroup
+	group.Add(sqlxDB, func(tx *sqlx.Tx) error { return nil }, func() error {
+		undone = append(undone, "first")
+		return nil
+	})
+	group.Add(sqlxDB, func(tx *sqlx.Tx) error { return nil }, func() error {
+		undone = append(undone, "second")
+		return compErr
+	})
+	group.Add(sqlxDB, func(tx *sqlx.Tx) error { return txErr }, func() error {
+		undone = append(undone, "third")
+		return nil
+	})
+
+	err = group.Execute(context.Background())
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if !reflect.DeepEqual(undone, []string{"second", "first"}) {
+		t.Errorf("expected compensation order [second first], got %v", undone)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
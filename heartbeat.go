@@ -0,0 +1,76 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrHeartbeatFailed is returned when a WithHeartbeat query failed while the
+// transaction function was running; the transaction is rolled back
+var ErrHeartbeatFailed = errors.New("transaction heartbeat failed")
+
+// WithHeartbeat runs SELECT 1 on the transaction's own connection every interval
+// while the transaction function runs, so a transaction that sits idle, e.g. waiting
+// for an external call, is not closed by MySQL's wait_timeout. The heartbeat stops
+// before the commit or rollback and when ctx is done. Queries are serialized with
+// the function's own queries by database/sql, but a heartbeat between two Next calls
+// of an open result set confuses most drivers, so avoid it while streaming rows.
+func WithHeartbeat(interval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.HeartbeatInterval = interval
+	}
+}
+
+// heartbeat pings a transaction in the background until stopped
+type heartbeat struct {
+	done chan struct{}
+	exit chan struct{}
+	once sync.Once
+	err  error
+}
+
+// startHeartbeat starts the configured heartbeat on tx. Calling stop waits for the
+// heartbeat goroutine to exit and returns the heartbeat failure, if any; it may be
+// called more than once.
+func startHeartbeat(ctx context.Context, tx *sqlx.Tx, config *Config) (stop func() error) {
+	if config.HeartbeatInterval <= 0 {
+		return func() error { return nil }
+	}
+
+	h := &heartbeat{done: make(chan struct{}), exit: make(chan struct{})}
+	go h.run(ctx, tx, config.HeartbeatInterval)
+	return func() error {
+		h.once.Do(func() {
+			close(h.done)
+			<-h.exit
+		})
+		return h.err
+	}
+}
+
+func (h *heartbeat) run(ctx context.Context, tx *sqlx.Tx, interval time.Duration) {
+	defer close(h.exit)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := tx.ExecContext(ctx, "SELECT 1"); err != nil {
+				if ctx.Err() == nil {
+					h.err = fmt.Errorf("%w: %w", ErrHeartbeatFailed, err)
+				}
+				return
+			}
+		case <-h.done:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
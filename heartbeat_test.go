@@ -0,0 +1,64 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		time.Sleep(45 * time.Millisecond) // one heartbeat at 30ms
+		return nil, nil
+	}, WithHeartbeat(30*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithHeartbeat_FailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT 1").WillReturnError(errors.New("invalid connection"))
+	mock.ExpectRollback()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		time.Sleep(30 * time.Millisecond)
+		return nil, nil
+	}, WithHeartbeat(10*time.Millisecond))
+
+	if !errors.Is(err, ErrHeartbeatFailed) {
+		t.Errorf("expected ErrHeartbeatFailed, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,155 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WithOnBegin registers a hook that runs after BEGIN and the built-in setup statements,
+// before the transaction function is called. Hooks run in registration order; the
+// first failure aborts the sequence and rolls the transaction back.
+func WithOnBegin(fn func(ctx context.Context, tx *sqlx.Tx) error) ConfigOption {
+	return func(c *Config) {
+		c.OnBegin = append(c.OnBegin, fn)
+	}
+}
+
+// WithOnCommit registers a hook that runs after the transaction commits successfully.
+// Hooks run in registration order.
+func WithOnCommit(fn func()) ConfigOption {
+	return func(c *Config) {
+		c.OnCommit = append(c.OnCommit, fn)
+	}
+}
+
+// WithOnRollback registers a hook that runs after the transaction is rolled back,
+// whether triggered by an error, a failed commit or a panic. Hooks run in registration order.
+func WithOnRollback(fn func(err error)) ConfigOption {
+	return func(c *Config) {
+		c.OnRollback = append(c.OnRollback, fn)
+	}
+}
+
+// WithOnError lets fn decide whether an error returned by the transaction function
+// rolls the transaction back. If fn returns false the transaction is committed anyway,
+// the commit hooks run, and the error is still returned to the caller without being
+// retried. Committing after a failure is unusual and dangerous: the function may have
+// stopped halfway, and on PostgreSQL a failed statement aborts the transaction, so
+// the COMMIT itself fails. Reserve it for errors the function raises deliberately,
+// such as soft validation failures, after all its writes are done.
+func WithOnError(fn func(ctx context.Context, err error) (shouldRollback bool)) ConfigOption {
+	return func(c *Config) {
+		c.OnError = fn
+	}
+}
+
+// WithPostCommitAction queues fn to run after a successful commit and the commit hooks,
+// e.g. to send emails or invalidate caches. Actions receive the transaction context and
+// run in registration order. Their failures cannot undo the commit: every action runs,
+// and any errors are returned together as a PostCommitError, inside the GroupedError
+// that also holds any commit hook failures.
+func WithPostCommitAction(fn func(ctx context.Context) error) ConfigOption {
+	return func(c *Config) {
+		c.PostCommitActions = append(c.PostCommitActions, fn)
+	}
+}
+
+// WithPanicHandler registers a handler that receives the value recovered from a panic
+// in the transaction function, after the transaction has been rolled back. The panic
+// is always re-raised once the handlers return.
+func WithPanicHandler(fn func(ctx context.Context, panicVal any)) ConfigOption {
+	return func(c *Config) {
+		c.PanicHandlers = append(c.PanicHandlers, fn)
+	}
+}
+
+// WithOnPanic registers a panic reporting hook, e.g. for Sentry. It is called with the
+// transaction context, which carries any observer trace span, after the rollback and
+// before the original panic is re-raised. It behaves exactly like WithPanicHandler.
+func WithOnPanic(fn func(ctx context.Context, recovered any)) ConfigOption {
+	return WithPanicHandler(fn)
+}
+
+// runBeginHooks calls every begin hook in order, stopping at the first failure
+func runBeginHooks(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	for _, hook := range config.OnBegin {
+		if err := hook(ctx, tx); err != nil {
+			return fmt.Errorf("begin hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// runAfterCommit runs the commit hooks and then the post-commit actions, returning
+// every failure in a GroupedError
+func runAfterCommit(ctx context.Context, db Executer, config *Config) error {
+	var errs []error
+	for _, hook := range config.OnCommit {
+		if err := callHook(func() { hook() }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := runPostCommitActions(ctx, db, config); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return GroupedError{Errs: errs}
+	}
+	return nil
+}
+
+// runPostCommitActions calls every post-commit action, then refreshes the views of
+// WithRefreshMaterializedView on db, and collects their failures
+func runPostCommitActions(ctx context.Context, db Executer, config *Config) error {
+	var errs []error
+	for _, action := range config.PostCommitActions {
+		var actionErr error
+		if err := callHook(func() { actionErr = action(ctx) }); err != nil {
+			actionErr = err
+		}
+		if actionErr != nil {
+			errs = append(errs, actionErr)
+		}
+	}
+	for _, view := range config.RefreshViews {
+		if err := refreshMaterializedView(ctx, db, view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return PostCommitError{Errs: errs}
+	}
+	return nil
+}
+
+// runRollbackHooks calls every rollback hook with cause and returns the first hook failure
+func runRollbackHooks(config *Config, cause error) error {
+	var firstErr error
+	for _, hook := range config.OnRollback {
+		if err := callHook(func() { hook(cause) }); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// runPanicHandlers calls every panic handler, ignoring handler panics so the
+// original panic is the one that is re-raised
+func runPanicHandlers(ctx context.Context, config *Config, panicVal any) {
+	for _, handler := range config.PanicHandlers {
+		_ = callHook(func() { handler(ctx, panicVal) })
+	}
+}
+
+// callHook runs fn and converts a panic into an error
+func callHook(fn func()) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("transaction hook panicked: %v", p)
+		}
+	}()
+	fn()
+	return nil
+}
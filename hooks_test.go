@@ -0,0 +1,498 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_OnCommitHooksRunInOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	var order []string
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	},
+		WithOnCommit(func() { order = append(order, "first") }),
+		WithOnCommit(func() { order = append(order, "second") }),
+		WithOnRollback(func(err error) { order = append(order, "rollback") }),
+	)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected hooks %v, got %v", want, order)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnRollbackReceivesError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	var got error
+	committed := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	},
+		WithOnCommit(func() { committed = true }),
+		WithOnRollback(func(err error) { got = err }),
+	)
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if !errors.Is(got, txErr) {
+		t.Errorf("expected rollback hook to receive test error, got %v", got)
+	}
+	if committed {
+		t.Error("expected commit hook not to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnRollbackRunsOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	rolledBack := false
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic, but function did not panic")
+		}
+		if !rolledBack {
+			t.Error("expected rollback hook to run")
+		}
+	}()
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		panic("test panic")
+	}, WithOnRollback(func(err error) { rolledBack = true }))
+}
+
+func TestExecuteContext_HookPanicSurfacesAsError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	secondRan := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	},
+		WithOnCommit(func() { panic("hook panic") }),
+		WithOnCommit(func() { secondRan = true }),
+	)
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+	if !secondRan {
+		t.Error("expected remaining hooks to run after a hook panic")
+	}
+}
+
+func TestExecuteContext_OnBeginFailureAbortsSequence(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL app.user_id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	hookErr := errors.New("hook failed")
+	secondRan := false
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	},
+		WithOnBegin(func(ctx context.Context, tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "SET LOCAL app.user_id = '42'")
+			if err != nil {
+				return err
+			}
+			return hookErr
+		}),
+		WithOnBegin(func(ctx context.Context, tx *sqlx.Tx) error {
+			secondRan = true
+			return nil
+		}),
+	)
+
+	if !errors.Is(err, hookErr) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+	if secondRan {
+		t.Error("expected second begin hook not to run")
+	}
+	if called {
+		t.Error("expected transaction function not to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_PanicHandlerThenRepanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	var handled any
+	defer func() {
+		r := recover()
+		if r != "test panic" {
+			t.Errorf("expected original panic to be re-raised, got %v", r)
+		}
+		if handled != "test panic" {
+			t.Errorf("expected handler to receive panic value, got %v", handled)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	}()
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		panic("test panic")
+	}, WithPanicHandler(func(ctx context.Context, panicVal any) {
+		handled = panicVal
+	}))
+}
+
+func TestExecuteContext_OnPanicSeesObserverContextAfterRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	type traceKey struct{}
+	observer := func(ctx context.Context, info TxInfo) (context.Context, func(bool, error)) {
+		return context.WithValue(ctx, traceKey{}, "trace-1"), func(bool, error) {}
+	}
+
+	var reported any
+	var traceID any
+	defer func() {
+		if r := recover(); r != "test panic" {
+			t.Errorf("expected original panic to be re-raised, got %v", r)
+		}
+		if reported != "test panic" || traceID != "trace-1" {
+			t.Errorf("expected hook to receive panic and trace context, got %v and %v", reported, traceID)
+		}
+	}()
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		panic("test panic")
+	}, WithObserver(observer), WithOnPanic(func(ctx context.Context, recovered any) {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("expected rollback before the panic hook: %s", err)
+		}
+		reported, traceID = recovered, ctx.Value(traceKey{})
+	}))
+}
+
+func TestExecuteContext_PostCommitActionsAggregateErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	emailErr := errors.New("smtp unavailable")
+	queueErr := errors.New("broker unavailable")
+	var order []string
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	},
+		WithOnCommit(func() { order = append(order, "commit hook") }),
+		WithPostCommitAction(func(ctx context.Context) error { order = append(order, "email"); return emailErr }),
+		WithPostCommitAction(func(ctx context.Context) error { order = append(order, "cache"); return nil }),
+		WithPostCommitAction(func(ctx context.Context) error { order = append(order, "queue"); return queueErr }),
+	)
+
+	if !IsPostCommitError(err) {
+		t.Fatalf("expected PostCommitError, got %v", err)
+	}
+	if !errors.Is(err, emailErr) || !errors.Is(err, queueErr) {
+		t.Errorf("expected both action errors in %v", err)
+	}
+	if want := []string{"commit hook", "email", "cache", "queue"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected order %v, got %v", want, order)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_PostCommitActionsSkippedOnRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	}, WithPostCommitAction(func(ctx context.Context) error { called = true; return nil }))
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if called {
+		t.Error("expected post-commit action not to run after a rollback")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnErrorCommitsSoftErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	softErr := errors.New("2 rows failed validation")
+	calls := 0
+	committed := false
+	result, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		calls++
+		return 8, softErr
+	}, WithMaxRetries(2), WithRetryPredicate(func(error, int) bool { return true }),
+		WithOnCommit(func() { committed = true }),
+		WithOnError(func(ctx context.Context, err error) bool {
+			return !errors.Is(err, softErr)
+		}))
+
+	if err != softErr {
+		t.Errorf("expected the function's error to be returned unchanged, got %v", err)
+	}
+	if result != 8 {
+		t.Errorf("expected result to be 8, got %d", result)
+	}
+	if !committed {
+		t.Error("expected the commit hooks to run")
+	}
+	if calls != 1 {
+		t.Errorf("expected a committed transaction not to be retried, got %d calls", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnErrorRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	var seen error
+	boom := errors.New("boom")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, boom
+	}, WithOnError(func(ctx context.Context, err error) bool {
+		seen = err
+		return true
+	}))
+
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+	if seen != boom {
+		t.Errorf("expected the hook to see boom, got %v", seen)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_GroupedErrorCollectsHookFailures(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	actionErr := errors.New("cache unavailable")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	},
+		WithOnCommit(func() { panic("first hook") }),
+		WithOnCommit(func() { panic("second hook") }),
+		WithPostCommitAction(func(ctx context.Context) error { return actionErr }),
+	)
+
+	var grouped GroupedError
+	if !errors.As(err, &grouped) {
+		t.Fatalf("expected GroupedError, got %v", err)
+	}
+	if len(grouped.Errors()) != 3 {
+		t.Errorf("expected 3 collected errors, got %d: %v", len(grouped.Errors()), grouped.Errors())
+	}
+	if !IsPostCommitError(err) || !errors.Is(err, actionErr) {
+		t.Errorf("expected the post-commit failure to be matched through the GroupedError, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnErrorKeepsErrorWhenCommitFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	commitErr := errors.New("current transaction is aborted")
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(commitErr)
+
+	softErr := errors.New("2 rows failed validation")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, softErr
+	}, WithOnError(func(ctx context.Context, err error) bool { return false }))
+
+	if !errors.Is(err, softErr) {
+		t.Errorf("expected the function's error to be kept, got %v", err)
+	}
+	if !IsCommitError(err) || !errors.Is(err, commitErr) {
+		t.Errorf("expected the commit failure to be reported, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_OnErrorKeepsErrorWhenNotifyFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	notifyErr := errors.New("connection reset")
+	mock.ExpectBegin()
+	mock.ExpectExec("NOTIFY").WillReturnError(notifyErr)
+	mock.ExpectRollback()
+
+	softErr := errors.New("2 rows failed validation")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, softErr
+	}, WithNotifyOnCommit("imports", "done"),
+		WithOnError(func(ctx context.Context, err error) bool { return false }))
+
+	if !errors.Is(err, softErr) {
+		t.Errorf("expected the function's error to be kept, got %v", err)
+	}
+	if !errors.Is(err, notifyErr) {
+		t.Errorf("expected the notify failure to be reported, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,79 @@
+package sqlxtx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// IdempotencyStore persists transaction results by idempotency key. Check reports
+// whether a result was stored for key and returns it.
+type IdempotencyStore interface {
+	Check(ctx context.Context, key string) ([]byte, bool, error)
+	Store(ctx context.Context, key string, result []byte) error
+}
+
+// IdempotencyStoreError is returned when the result of a committed transaction could
+// not be encoded or stored under its idempotency key. The transaction was committed
+// and Result holds its result, so the call must not simply be retried: a retry would
+// repeat the side effects the key was meant to prevent.
+type IdempotencyStoreError struct {
+	Key    string
+	Result any
+	Err    error
+}
+
+func (e IdempotencyStoreError) Error() string {
+	return fmt.Sprintf("transaction committed but its result could not be stored for idempotency key %q: %v", e.Key, e.Err)
+}
+
+func (e IdempotencyStoreError) Unwrap() error {
+	return e.Err
+}
+
+// WithIdempotencyKey deduplicates replayed transactions. If store already holds a
+// result for key it is decoded and returned without touching the database; otherwise
+// the result is JSON-encoded and stored after a successful commit. A failure to store
+// it is returned, along with the result, as an IdempotencyStoreError.
+func WithIdempotencyKey(key string, store IdempotencyStore) ConfigOption {
+	return func(c *Config) {
+		c.IdempotencyKey = key
+		c.IdempotencyStore = store
+	}
+}
+
+// checkIdempotency returns the stored result for the configured key, if any
+func checkIdempotency[T any](ctx context.Context, config *Config) (result T, found bool, err error) {
+	if config.IdempotencyKey == "" {
+		return result, false, nil
+	}
+
+	data, found, err := config.IdempotencyStore.Check(ctx, config.IdempotencyKey)
+	if err != nil {
+		return result, false, fmt.Errorf("failed to check idempotency key %q: %w", config.IdempotencyKey, err)
+	}
+	if !found {
+		return result, false, nil
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, false, fmt.Errorf("failed to decode result for idempotency key %q: %w", config.IdempotencyKey, err)
+	}
+	return result, true, nil
+}
+
+// storeIdempotency saves a committed result under the configured key
+func storeIdempotency(ctx context.Context, config *Config, result any) error {
+	if config.IdempotencyKey == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return IdempotencyStoreError{Key: config.IdempotencyKey, Result: result, Err: fmt.Errorf("failed to encode result: %w", err)}
+	}
+	if err := config.IdempotencyStore.Store(ctx, config.IdempotencyKey, data); err != nil {
+		return IdempotencyStoreError{Key: config.IdempotencyKey, Result: result, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type memoryIdempotencyStore map[string][]byte
+
+func (s memoryIdempotencyStore) Check(ctx context.Context, key string) ([]byte, bool, error) {
+	data, ok := s[key]
+	return data, ok, nil
+}
+
+func (s memoryIdempotencyStore) Store(ctx context.Context, key string, result []byte) error {
+	s[key] = result
+	return nil
+}
+
+func TestExecuteContext_IdempotencyKeyReplaysStoredResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	type order struct {
+		ID    int    `json:"id"`
+		State string `json:"state"`
+	}
+
+	store := memoryIdempotencyStore{}
+	calls := 0
+	txFunc := func(tx *sqlx.Tx) (order, error) {
+		calls++
+		return order{ID: 7, State: "created"}, nil
+	}
+
+	first, err := ExecuteContext(context.Background(), sqlxDB, txFunc, WithIdempotencyKey("req-1", store))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if string(store["req-1"]) != `{"id":7,"state":"created"}` {
+		t.Errorf("expected committed result to be stored, got %s", store["req-1"])
+	}
+
+	second, err := ExecuteContext(context.Background(), sqlxDB, txFunc, WithIdempotencyKey("req-1", store))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if second != first {
+		t.Errorf("expected replayed result %v, got %v", first, second)
+	}
+	if calls != 1 {
+		t.Errorf("expected the transaction function to run once, got %d", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_IdempotencyKeyNotStoredOnRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	store := memoryIdempotencyStore{}
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 0, txErr
+	}, WithIdempotencyKey("req-1", store))
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if _, ok := store["req-1"]; ok {
+		t.Error("expected no result to be stored after rollback")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type failingIdempotencyStore struct{ err error }
+
+func (s failingIdempotencyStore) Check(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (s failingIdempotencyStore) Store(ctx context.Context, key string, result []byte) error {
+	return s.err
+}
+
+func TestExecuteContext_IdempotencyStoreFailureKeepsResult(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	storeErr := errors.New("redis unavailable")
+	result, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 42, nil
+	}, WithIdempotencyKey("req-1", failingIdempotencyStore{storeErr}))
+
+	var storeFailure IdempotencyStoreError
+	if !errors.As(err, &storeFailure) {
+		t.Fatalf("expected an IdempotencyStoreError, got %v", err)
+	}
+	if !errors.Is(err, storeErr) {
+		t.Errorf("expected the store error to be wrapped, got %v", err)
+	}
+	if storeFailure.Key != "req-1" || storeFailure.Result != 42 {
+		t.Errorf("expected key req-1 and result 42, got %q and %v", storeFailure.Key, storeFailure.Result)
+	}
+	if result != 42 {
+		t.Errorf("expected the committed result to be returned, got %d", result)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,211 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WithQueryInterceptor registers fn to rewrite every query run through an
+// InterceptedTx, e.g. to add a /* request_id=X */ comment. Interceptors are applied
+// in registration order. Only ExecuteIntercepted hands out an InterceptedTx, so every
+// other entry point rejects this option with ErrInvalidOption.
+func WithQueryInterceptor(fn func(ctx context.Context, query string) string) ConfigOption {
+	return func(c *Config) {
+		c.QueryInterceptors = append(c.QueryInterceptors, fn)
+	}
+}
+
+// InterceptedTx wraps a transaction and passes queries through the configured
+// interceptors before running them. It deliberately does not embed *sqlx.Tx, so no
+// query method can bypass the interceptors; Unwrap returns the underlying transaction
+// for helpers that need one, and queries run on it are not intercepted.
+type InterceptedTx struct {
+	tx           *sqlx.Tx
+	interceptors []func(ctx context.Context, query string) string
+	recorder     *queryRecorder
+}
+
+// InterceptedTxFunc is a TxFunc that receives an InterceptedTx
+type InterceptedTxFunc[T any] func(tx *InterceptedTx) (T, error)
+
+// ExecuteIntercepted runs fn within a transaction like ExecuteContext, handing it an
+// InterceptedTx that applies the WithQueryInterceptor hooks
+func ExecuteIntercepted[T any](ctx context.Context, db Executer, fn InterceptedTxFunc[T], options ...ConfigOption) (T, error) {
+	var config *Config
+	var recorder *queryRecorder
+
+	start := time.Now()
+	result, err := execute(ctx, db, entryIntercepted, func(c *Config) (ContextTxFunc[T], error) {
+		config = c
+		if config.ExplainLogger != nil {
+			recorder = &queryRecorder{}
+		}
+		return func(_ context.Context, tx *sqlx.Tx) (T, error) {
+			recorder.reset()
+			return fn(&InterceptedTx{tx: tx, interceptors: config.QueryInterceptors, recorder: recorder})
+		}, nil
+	}, options)
+
+	if elapsed := time.Since(start); recorder != nil && elapsed > config.ExplainThreshold {
+		explainQueries(ctx, db, config.ExplainLogger, elapsed, recorder.queries)
+	}
+	return result, err
+}
+
+// rewrite applies every interceptor to query in order
+func (tx *InterceptedTx) rewrite(ctx context.Context, query string) string {
+	for _, fn := range tx.interceptors {
+		query = fn(ctx, query)
+	}
+	return query
+}
+
+// intercept rewrites query and records the result for WithExplainAutoLog
+func (tx *InterceptedTx) intercept(ctx context.Context, query string, args []any) string {
+	query = tx.rewrite(ctx, query)
+	tx.recorder.record(query, args)
+	return query
+}
+
+// Unwrap returns the underlying transaction. Queries run on it bypass the interceptors.
+func (tx *InterceptedTx) Unwrap() *sqlx.Tx {
+	return tx.tx
+}
+
+func (tx *InterceptedTx) DriverName() string {
+	return tx.tx.DriverName()
+}
+
+func (tx *InterceptedTx) Rebind(query string) string {
+	return tx.tx.Rebind(query)
+}
+
+func (tx *InterceptedTx) BindNamed(query string, arg any) (string, []any, error) {
+	return tx.tx.BindNamed(query, arg)
+}
+
+func (tx *InterceptedTx) Exec(query string, args ...any) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.tx.ExecContext(ctx, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) MustExec(query string, args ...any) sql.Result {
+	return tx.MustExecContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) MustExecContext(ctx context.Context, query string, args ...any) sql.Result {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (tx *InterceptedTx) Query(query string, args ...any) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.tx.QueryContext(ctx, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) QueryRow(query string, args ...any) *sql.Row {
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.tx.QueryRowContext(ctx, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) Queryx(query string, args ...any) (*sqlx.Rows, error) {
+	return tx.QueryxContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	return tx.tx.QueryxContext(ctx, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) QueryRowx(query string, args ...any) *sqlx.Row {
+	return tx.QueryRowxContext(context.Background(), query, args...)
+}
+
+func (tx *InterceptedTx) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	return tx.tx.QueryRowxContext(ctx, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) Get(dest any, query string, args ...any) error {
+	return tx.GetContext(context.Background(), dest, query, args...)
+}
+
+func (tx *InterceptedTx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return tx.tx.GetContext(ctx, dest, tx.intercept(ctx, query, args), args...)
+}
+
+func (tx *InterceptedTx) Select(dest any, query string, args ...any) error {
+	return tx.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (tx *InterceptedTx) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return tx.tx.SelectContext(ctx, dest, tx.intercept(ctx, query, args), args...)
+}
+
+// NamedExec binds the named parameters in query from arg, then runs it like Exec
+func (tx *InterceptedTx) NamedExec(query string, arg any) (sql.Result, error) {
+	return tx.NamedExecContext(context.Background(), query, arg)
+}
+
+// NamedExecContext binds the named parameters in query from arg, then runs it like ExecContext
+func (tx *InterceptedTx) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	bound, args, err := tx.tx.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return tx.ExecContext(ctx, bound, args...)
+}
+
+// NamedQuery binds the named parameters in query from arg, then runs it like Queryx
+func (tx *InterceptedTx) NamedQuery(query string, arg any) (*sqlx.Rows, error) {
+	return tx.NamedQueryContext(context.Background(), query, arg)
+}
+
+// NamedQueryContext binds the named parameters in query from arg, then runs it like QueryxContext
+func (tx *InterceptedTx) NamedQueryContext(ctx context.Context, query string, arg any) (*sqlx.Rows, error) {
+	bound, args, err := tx.tx.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return tx.QueryxContext(ctx, bound, args...)
+}
+
+// Prepare rewrites query once, when it is prepared. Like the other prepare methods it
+// does not record the statement for WithExplainAutoLog, as its arguments are only
+// known when it runs.
+func (tx *InterceptedTx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.PrepareContext(context.Background(), query)
+}
+
+func (tx *InterceptedTx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.tx.PrepareContext(ctx, tx.rewrite(ctx, query))
+}
+
+func (tx *InterceptedTx) Preparex(query string) (*sqlx.Stmt, error) {
+	return tx.PreparexContext(context.Background(), query)
+}
+
+func (tx *InterceptedTx) PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	return tx.tx.PreparexContext(ctx, tx.rewrite(ctx, query))
+}
+
+func (tx *InterceptedTx) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	return tx.PrepareNamedContext(context.Background(), query)
+}
+
+func (tx *InterceptedTx) PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error) {
+	return tx.tx.PrepareNamedContext(ctx, tx.rewrite(ctx, query))
+}
@@ -0,0 +1,141 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteIntercepted_RewritesQueries(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("/* request_id=abc */ UPDATE users SET name = $1 -- traced").
+		WithArgs("Jane").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("/* request_id=abc */ SELECT COUNT(*) FROM users -- traced").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectCommit()
+
+	type requestIDKey struct{}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+
+	count, err := ExecuteIntercepted(ctx, sqlxDB, func(tx *InterceptedTx) (int, error) {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET name = $1", "Jane"); err != nil {
+			return 0, err
+		}
+		var count int
+		err := tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM users")
+		return count, err
+	},
+		WithQueryInterceptor(func(ctx context.Context, query string) string {
+			return "/* request_id=" + ctx.Value(requestIDKey{}).(string) + " */ " + query
+		}),
+		WithQueryInterceptor(func(ctx context.Context, query string) string {
+			return query + " -- traced"
+		}),
+	)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected count 3, got %d", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_RejectsQueryInterceptors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	ctx := context.Background()
+
+	for _, option := range []ConfigOption{WithPGComment("job=invoice"), WithCorrelationID("req-1")} {
+		_, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+			t.Error("expected the transaction function not to run")
+			return nil, nil
+		}, option)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("expected ErrInvalidOption from ExecuteContext, got %v", err)
+		}
+
+		_, err = ExecuteLazy(ctx, sqlxDB, func(tx *LazyTx) (any, error) {
+			return nil, nil
+		}, option)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("expected ErrInvalidOption from ExecuteLazy, got %v", err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestInterceptedTx_AllQueryMethodsIntercepted(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("/* t */ DELETE FROM sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("/* t */ UPDATE users SET name = $1 WHERE id = $2").
+		WithArgs("Jane", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("/* t */ SELECT name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Jane"))
+	mock.ExpectPrepare("/* t */ INSERT INTO audit (msg) VALUES ($1)")
+	mock.ExpectExec("SELECT 1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteIntercepted(context.Background(), sqlxDB, func(tx *InterceptedTx) (any, error) {
+		if _, err := tx.Exec("DELETE FROM sessions"); err != nil {
+			return nil, err
+		}
+		user := map[string]any{"name": "Jane", "id": 1}
+		if _, err := tx.NamedExec("UPDATE users SET name = :name WHERE id = :id", user); err != nil {
+			return nil, err
+		}
+		var names []string
+		if err := tx.Select(&names, "SELECT name FROM users"); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Preparex("INSERT INTO audit (msg) VALUES ($1)"); err != nil {
+			return nil, err
+		}
+		// Unwrap is the explicit escape hatch that bypasses the interceptors
+		_, err := tx.Unwrap().Exec("SELECT 1")
+		return nil, err
+	}, WithQueryInterceptor(func(ctx context.Context, query string) string {
+		return "/* t */ " + query
+	}))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
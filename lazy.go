@@ -0,0 +1,156 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// LazyTx opens its transaction on first use, so code paths that never query do not
+// hold a pooled connection. It is not safe for concurrent use.
+type LazyTx struct {
+	ctx     context.Context
+	db      Executer
+	config  *Config
+	tx      *sqlx.Tx
+	release func()
+	err     error
+}
+
+// ExecuteLazy runs fn with a LazyTx. If fn issues a query, the transaction is begun
+// (including the configured setup statements and begin hooks) and then committed or
+// rolled back as by ExecuteContext; otherwise no transaction is opened at all.
+// Options that need the ExecuteContext machinery, such as retries, timeouts, observers,
+// stats, WithOnError and propagation, are rejected with ErrInvalidOption.
+func ExecuteLazy[T any](ctx context.Context, db Executer, fn func(tx *LazyTx) (T, error), options ...ConfigOption) (result T, err error) {
+	config := newConfig(options)
+	config.DriverName = db.DriverName()
+	if err = config.Validate(); err != nil {
+		return result, err
+	}
+	if err = config.validateEntryPoint(entryLazy); err != nil {
+		return result, err
+	}
+
+	lazy := &LazyTx{ctx: ctx, db: db, config: config}
+	defer func() {
+		if lazy.release != nil {
+			lazy.release()
+		}
+	}()
+	defer func() {
+		if p := recover(); p != nil {
+			if lazy.tx != nil {
+				_ = lazy.tx.Rollback()
+				_ = runRollbackHooks(config, fmt.Errorf("transaction panicked: %v", p))
+				runPanicHandlers(ctx, config, p)
+			}
+			panic(p)
+		}
+	}()
+
+	result, err = fn(lazy)
+	if err != nil && config.ErrorMapper != nil {
+		err = config.ErrorMapper.Map(err)
+	}
+	if lazy.tx == nil {
+		return result, err
+	}
+	return result, lazy.finish(err)
+}
+
+// Tx begins the transaction if it has not been begun yet and returns it
+func (l *LazyTx) Tx() (*sqlx.Tx, error) {
+	if l.tx != nil || l.err != nil {
+		return l.tx, l.err
+	}
+
+	tx, release, err := beginTx(l.ctx, l.db, l.config)
+	if err != nil {
+		l.err = BeginError{Err: err}
+		return nil, l.err
+	}
+	if err := prepareTx(l.ctx, tx, l.config); err != nil {
+		_ = tx.Rollback()
+		release()
+		l.err = err
+		return nil, err
+	}
+
+	l.tx = tx
+	l.release = release
+	return tx, nil
+}
+
+// Started reports whether the transaction has been begun
+func (l *LazyTx) Started() bool {
+	return l.tx != nil
+}
+
+// finish commits or rolls back a begun transaction depending on err
+func (l *LazyTx) finish(err error) error {
+	if err != nil {
+		if rollbackErr := l.tx.Rollback(); rollbackErr != nil {
+			err = RollbackError{Err: rollbackErr, Cause: err}
+		}
+		if hookErr := runRollbackHooks(l.config, err); hookErr != nil {
+			err = fmt.Errorf("%w (rollback hook error: %v)", err, hookErr)
+		}
+		return err
+	}
+
+	if err := notifyPostgres(l.ctx, l.tx, l.config); err != nil {
+		return l.finish(err)
+	}
+
+	if commitErr := commit(l.ctx, l.tx, l.config); commitErr != nil {
+		err = CommitError{Err: commitErr}
+		if hookErr := runRollbackHooks(l.config, err); hookErr != nil {
+			err = fmt.Errorf("%w (rollback hook error: %v)", err, hookErr)
+		}
+		return err
+	}
+	return runAfterCommit(l.ctx, l.db, l.config)
+}
+
+func (l *LazyTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	tx, err := l.Tx()
+	if err != nil {
+		return nil, err
+	}
+	return tx.ExecContext(ctx, query, args...)
+}
+
+func (l *LazyTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	tx, err := l.Tx()
+	if err != nil {
+		return nil, err
+	}
+	return tx.QueryContext(ctx, query, args...)
+}
+
+func (l *LazyTx) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	tx, err := l.Tx()
+	if err != nil {
+		return nil, err
+	}
+	return tx.QueryxContext(ctx, query, args...)
+}
+
+func (l *LazyTx) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	tx, err := l.Tx()
+	if err != nil {
+		return err
+	}
+	return tx.GetContext(ctx, dest, query, args...)
+}
+
+func (l *LazyTx) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	tx, err := l.Tx()
+	if err != nil {
+		return err
+	}
+	return tx.SelectContext(ctx, dest, query, args...)
+}
@@ -0,0 +1,138 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteLazy_NoQueryNoTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	committed := false
+	result, err := ExecuteLazy(context.Background(), sqlxDB, func(tx *LazyTx) (int, error) {
+		return 5, nil
+	}, WithOnCommit(func() { committed = true }))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if result != 5 {
+		t.Errorf("expected result 5, got %d", result)
+	}
+	if committed {
+		t.Error("expected no commit without a transaction")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteLazy_BeginsOnFirstQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	_, err = ExecuteLazy(ctx, sqlxDB, func(tx *LazyTx) (any, error) {
+		if tx.Started() {
+			t.Error("expected no transaction before the first query")
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET active = true"); err != nil {
+			return nil, err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE accounts SET active = true")
+		return nil, err
+	}, WithStatementTimeout(time.Second))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteLazy_RollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	txErr := errors.New("test error")
+	_, err = ExecuteLazy(ctx, sqlxDB, func(tx *LazyTx) (any, error) {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM users"); err != nil {
+			return nil, err
+		}
+		return nil, txErr
+	})
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteLazy_RejectsUnsupportedOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	options := []ConfigOption{
+		WithRetry(3, nil),
+		WithTimeout(time.Second),
+		WithStats(&TxStats{}),
+		WithOnError(func(ctx context.Context, err error) bool { return true }),
+		WithPropagation(PropagationRequired),
+	}
+	for _, option := range options {
+		_, err := ExecuteLazy(context.Background(), sqlxDB, func(tx *LazyTx) (any, error) {
+			t.Error("expected the function not to run")
+			return nil, nil
+		}, option)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("expected ErrInvalidOption, got %v", err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,68 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BeginFunc starts a transaction on db, see WithCustomBegin
+type BeginFunc func(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions) (*sqlx.Tx, error)
+
+// WithCustomBegin replaces the db.BeginTxx call with fn, for proxies or databases that
+// need a non-standard start, e.g. CockroachDB's SAVEPOINT cockroach_restart right
+// after BEGIN. fn receives the configured TxOptions (from WithTxOptions, WithReadOnly,
+// and so on) and decides how to apply them. Everything after BEGIN, setup statements
+// and begin hooks included, runs as usual. The database handle must be a *sqlx.DB or
+// a *PrimaryReplicaDB, which passes the primary or replica chosen for opts.
+func WithCustomBegin(fn BeginFunc) ConfigOption {
+	return func(c *Config) {
+		c.CustomBegin = fn
+	}
+}
+
+// begin starts a transaction on db with config.CustomBegin or db.BeginTxx
+func begin(ctx context.Context, db Executer, config *Config) (*sqlx.Tx, error) {
+	if config.CustomBegin == nil {
+		return db.BeginTxx(ctx, config.TxOptions)
+	}
+
+	switch d := db.(type) {
+	case *sqlx.DB:
+		return config.CustomBegin(ctx, d, config.TxOptions)
+	case *PrimaryReplicaDB:
+		return config.CustomBegin(ctx, d.route(config.TxOptions), config.TxOptions)
+	}
+	return nil, invalidOption("WithCustomBegin needs a *sqlx.DB or *PrimaryReplicaDB, got %T", db)
+}
+
+// WithCustomCommit replaces the tx.Commit call with fn, e.g. to run RELEASE SAVEPOINT
+// cockroach_restart before committing. fn normally ends with tx.Commit(); if it returns
+// nil without ending the transaction, the transaction is committed after it. If fn
+// fails, the transaction is rolled back and the error is returned as a CommitError,
+// with the rollback hooks run as for a failed COMMIT.
+func WithCustomCommit(fn func(ctx context.Context, tx *sqlx.Tx) error) ConfigOption {
+	return func(c *Config) {
+		c.CustomCommit = fn
+	}
+}
+
+// commit commits tx with config.CustomCommit or tx.Commit
+func commit(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	if config.CustomCommit == nil {
+		return tx.Commit()
+	}
+
+	if err := config.CustomCommit(ctx, tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return RollbackError{Err: rollbackErr, Cause: err}
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
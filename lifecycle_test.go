@@ -0,0 +1,159 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWithCustomBegin(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT cockroach_restart").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	var gotOpts *sql.TxOptions
+	crdbBegin := func(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions) (*sqlx.Tx, error) {
+		gotOpts = opts
+		tx, err := db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT cockroach_restart"); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		return tx, nil
+	}
+
+	txOpts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithTxOptions(txOpts), WithCustomBegin(crdbBegin))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotOpts == nil || gotOpts.Isolation != sql.LevelSerializable {
+		t.Errorf("expected the custom begin to receive the configured TxOptions, got %+v", gotOpts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithCustomBegin_PrimaryReplicaDB(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+	replica, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer replica.Close()
+
+	primaryDB := sqlx.NewDb(primary, "postgres")
+	db := NewPrimaryReplicaDB(primaryDB, sqlx.NewDb(replica, "postgres"))
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectCommit()
+
+	var got *sqlx.DB
+	_, err = ExecuteContext(context.Background(), db, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithCustomBegin(func(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions) (*sqlx.Tx, error) {
+		got = db
+		return db.BeginTxx(ctx, opts)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != primaryDB {
+		t.Error("expected the custom begin to receive the primary")
+	}
+
+	if err := primaryMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithCustomCommit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("RELEASE SAVEPOINT cockroach_restart").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	committed := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithOnCommit(func() { committed = true }), WithCustomCommit(func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT cockroach_restart"); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !committed {
+		t.Error("expected the commit hooks to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithCustomCommit_FailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("RELEASE SAVEPOINT").WillReturnError(&sqlStateError{"40001"})
+	mock.ExpectRollback()
+
+	rolledBack := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithOnRollback(func(error) { rolledBack = true }), WithCustomCommit(func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT cockroach_restart"); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}))
+
+	if !IsCommitError(err) {
+		t.Errorf("expected a CommitError, got %v", err)
+	}
+	if !rolledBack {
+		t.Error("expected the rollback hooks to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,39 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// activeTxs maps each transaction started by executeOnce to its TxInfo
+var activeTxs sync.Map
+
+// SelectForUpdate runs query with FOR UPDATE appended, locking the selected rows
+// until the transaction ends, and scans the rows into dest. It fails with
+// ErrReadOnlyTransaction inside a transaction started with WithReadOnly.
+func SelectForUpdate[T any](ctx context.Context, tx *sqlx.Tx, dest *[]T, query string, args ...any) error {
+	return selectLocked(ctx, tx, dest, query, "FOR UPDATE", args)
+}
+
+// SelectSkipLocked runs query with FOR UPDATE SKIP LOCKED appended, locking the
+// selected rows and skipping rows locked by other transactions, e.g. for job queues.
+// It fails with ErrReadOnlyTransaction inside a transaction started with WithReadOnly.
+func SelectSkipLocked[T any](ctx context.Context, tx *sqlx.Tx, dest *[]T, query string, args ...any) error {
+	return selectLocked(ctx, tx, dest, query, "FOR UPDATE SKIP LOCKED", args)
+}
+
+func selectLocked[T any](ctx context.Context, tx *sqlx.Tx, dest *[]T, query, clause string, args []any) error {
+	if info, ok := activeTxs.Load(tx); ok && info.(TxInfo).ReadOnly() {
+		return fmt.Errorf("%w: cannot use %s", ErrReadOnlyTransaction, clause)
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";") + " " + clause
+	if err := tx.SelectContext(ctx, dest, query, args...); err != nil {
+		return fmt.Errorf("failed to query %q: %w", query, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSelectForUpdateAndSkipLocked(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name FROM users WHERE id = $1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John"))
+	mock.ExpectQuery("SELECT id, name FROM users LIMIT 10 FOR UPDATE SKIP LOCKED").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Jane").AddRow(3, "Jim"))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	var locked, jobs []rowUser
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		if err := SelectForUpdate(ctx, tx, &locked, "SELECT id, name FROM users WHERE id = $1", 1); err != nil {
+			return err
+		}
+		return SelectSkipLocked(ctx, tx, &jobs, "SELECT id, name FROM users LIMIT 10;")
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(locked) != 1 || len(jobs) != 2 {
+		t.Errorf("expected 1 locked row and 2 jobs, got %v and %v", locked, jobs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSelectForUpdate_ReadOnlyTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		var users []rowUser
+		return SelectForUpdate(ctx, tx, &users, "SELECT id, name FROM users")
+	}, WithReadOnly())
+
+	if !errors.Is(err, ErrReadOnlyTransaction) {
+		t.Errorf("expected ErrReadOnlyTransaction, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
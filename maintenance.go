@@ -0,0 +1,68 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// MaintenanceError is returned when a WithPostCommitMaintenance statement fails. The
+// transaction was committed and Result holds its result.
+type MaintenanceError struct {
+	Statement string
+	Result    any
+	Err       error
+}
+
+func (e MaintenanceError) Error() string {
+	return fmt.Sprintf("post-commit maintenance %q failed: %v", e.Statement, e.Err)
+}
+
+func (e MaintenanceError) Unwrap() error {
+	return e.Err
+}
+
+// WithPostCommitMaintenance runs statements, in order and outside any transaction,
+// after a successful commit, e.g. VACUUM after a bulk import. The database handle
+// must implement ExecContext, as *sqlx.DB and *PrimaryReplicaDB do. The first failure
+// stops the sequence and is returned as a MaintenanceError. Multiple calls stack.
+func WithPostCommitMaintenance(statements ...string) ConfigOption {
+	return func(c *Config) {
+		c.MaintenanceStatements = append(c.MaintenanceStatements, statements...)
+	}
+}
+
+// WithSQLiteVacuumAnalyze runs VACUUM and ANALYZE after a successful commit to
+// reclaim space and refresh planner statistics (SQLite)
+func WithSQLiteVacuumAnalyze() ConfigOption {
+	return WithPostCommitMaintenance("VACUUM", "ANALYZE")
+}
+
+// dbExecer is implemented by database handles that can run statements outside a
+// transaction, such as *sqlx.DB
+type dbExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// runMaintenance runs the configured maintenance statements on db
+func runMaintenance(ctx context.Context, db Executer, config *Config, result any) error {
+	if len(config.MaintenanceStatements) == 0 {
+		return nil
+	}
+
+	execer, ok := db.(dbExecer)
+	if !ok {
+		return MaintenanceError{
+			Statement: config.MaintenanceStatements[0],
+			Result:    result,
+			Err:       fmt.Errorf("database handle %T cannot run statements outside a transaction", db),
+		}
+	}
+
+	for _, statement := range config.MaintenanceStatements {
+		if _, err := execer.ExecContext(ctx, statement); err != nil {
+			return MaintenanceError{Statement: statement, Result: result, Err: err}
+		}
+	}
+	return nil
+}
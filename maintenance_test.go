@@ -0,0 +1,71 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_SQLiteVacuumAnalyzeAfterCommit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlite3")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectExec("VACUUM").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ANALYZE").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSQLiteVacuumAnalyze())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_MaintenanceErrorCarriesResult(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlite3")
+
+	vacuumErr := errors.New("database is locked")
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectExec("VACUUM").WillReturnError(vacuumErr)
+
+	result, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 42, nil
+	}, WithSQLiteVacuumAnalyze())
+
+	var maintErr MaintenanceError
+	if !errors.As(err, &maintErr) {
+		t.Fatalf("expected MaintenanceError, got %v", err)
+	}
+	if !errors.Is(err, vacuumErr) || maintErr.Statement != "VACUUM" || maintErr.Result != 42 {
+		t.Errorf("unexpected maintenance error %+v", maintErr)
+	}
+	if result != 42 {
+		t.Errorf("expected result 42, got %d", result)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,51 @@
+package sqlxtx
+
+import (
+	"context"
+)
+
+// TxManager holds a database handle and a base set of options so that
+// domain-specific transaction settings can be configured once and shared.
+type TxManager struct {
+	db      Executer
+	options []ConfigOption
+}
+
+// NewTxManager creates a TxManager whose transactions use the given default options
+func NewTxManager(db Executer, options ...ConfigOption) *TxManager {
+	return &TxManager{
+		db:      db,
+		options: append([]ConfigOption(nil), options...),
+	}
+}
+
+// DB returns the database handle used by the manager
+func (m *TxManager) DB() Executer {
+	return m.db
+}
+
+// Options returns a copy of the manager's default options
+func (m *TxManager) Options() []ConfigOption {
+	return append([]ConfigOption(nil), m.options...)
+}
+
+// Execute runs txFunc within a transaction using the manager's default options.
+// Go methods cannot be generic; use ExecuteManaged for a typed result.
+func (m *TxManager) Execute(ctx context.Context, txFunc TxFunc[any]) (any, error) {
+	return ExecuteManaged(ctx, m, txFunc)
+}
+
+// ExecuteWith runs txFunc with the manager's defaults followed by options,
+// so options override the defaults for this call only
+func (m *TxManager) ExecuteWith(ctx context.Context, txFunc TxFunc[any], options ...ConfigOption) (any, error) {
+	return ExecuteManaged(ctx, m, txFunc, options...)
+}
+
+// ExecuteManaged runs txFunc through mgr with a typed result. options are
+// applied after the manager's defaults and override them for this call only.
+func ExecuteManaged[T any](ctx context.Context, mgr *TxManager, txFunc TxFunc[T], options ...ConfigOption) (T, error) {
+	merged := make([]ConfigOption, 0, len(mgr.options)+len(options))
+	merged = append(merged, mgr.options...)
+	merged = append(merged, options...)
+	return ExecuteContext(ctx, mgr.db, txFunc, merged...)
+}
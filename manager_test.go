@@ -0,0 +1,62 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTxManager_ExecuteWithOverridesDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	var infos []TxInfo
+	record := WithObserver(func(ctx context.Context, info TxInfo) (context.Context, func(committed bool, err error)) {
+		infos = append(infos, info)
+		return ctx, nil
+	})
+
+	mgr := NewTxManager(sqlxDB, WithSerializable(), record)
+	ctx := context.Background()
+
+	if _, err := mgr.Execute(ctx, func(tx *sqlx.Tx) (any, error) { return nil, nil }); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	result, err := ExecuteManaged(ctx, mgr, func(tx *sqlx.Tx) (int, error) {
+		return 5, nil
+	}, WithReadCommitted(), WithReadOnly())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != 5 {
+		t.Errorf("expected result to be 5, got %v", result)
+	}
+
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 observed transactions, got %d", len(infos))
+	}
+	if infos[0].IsolationLevel() != sql.LevelSerializable || infos[0].ReadOnly() {
+		t.Errorf("expected manager defaults on first call, got %+v", infos[0].TxOptions)
+	}
+	if infos[1].IsolationLevel() != sql.LevelReadCommitted || !infos[1].ReadOnly() {
+		t.Errorf("expected call-site overrides on second call, got %+v", infos[1].TxOptions)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,40 @@
+package sqlxtx
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxMiddleware wraps a TxFunc with cross-cutting behavior
+type TxMiddleware[T any] func(TxFunc[T]) TxFunc[T]
+
+// Chain wraps fn with the given middlewares. The first middleware is the outermost,
+// so Chain(fn, a, b) behaves like a(b(fn)).
+func Chain[T any](fn TxFunc[T], middlewares ...TxMiddleware[T]) TxFunc[T] {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fn = middlewares[i](fn)
+	}
+	return fn
+}
+
+// LoggingMiddleware logs the start, outcome, and duration of each transaction function call
+func LoggingMiddleware[T any](logger *slog.Logger) TxMiddleware[T] {
+	return func(next TxFunc[T]) TxFunc[T] {
+		return func(tx *sqlx.Tx) (T, error) {
+			start := time.Now()
+			logger.Debug("transaction function started")
+
+			result, err := next(tx)
+
+			duration := time.Since(start)
+			if err != nil {
+				logger.Error("transaction function failed", "duration", duration, "error", err)
+			} else {
+				logger.Info("transaction function succeeded", "duration", duration)
+			}
+			return result, err
+		}
+	}
+}
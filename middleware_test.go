@@ -0,0 +1,58 @@
+package sqlxtx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestChain_AppliesOutermostFirst(t *testing.T) {
+	var order []string
+	trace := func(name string) TxMiddleware[int] {
+		return func(next TxFunc[int]) TxFunc[int] {
+			return func(tx *sqlx.Tx) (int, error) {
+				order = append(order, name+" before")
+				result, err := next(tx)
+				order = append(order, name+" after")
+				return result, err
+			}
+		}
+	}
+
+	fn := Chain(func(tx *sqlx.Tx) (int, error) {
+		order = append(order, "fn")
+		return 1, nil
+	}, trace("outer"), trace("inner"))
+
+	if _, err := fn(nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []string{"outer before", "inner before", "fn", "inner after", "outer after"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("expected %v, got %v", want, order)
+	}
+}
+
+func TestLoggingMiddleware_LogsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	fn := Chain(func(tx *sqlx.Tx) (any, error) {
+		return nil, errors.New("boom")
+	}, LoggingMiddleware[any](logger))
+
+	if _, err := fn(nil); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "transaction function started") || !strings.Contains(out, "error=boom") {
+		t.Errorf("unexpected log output: %s", out)
+	}
+}
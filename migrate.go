@@ -0,0 +1,57 @@
+package sqlxtx
+
+import (
+	"reflect"
+)
+
+// ConfigVersion is the Config layout version written by this release. Version 1
+// introduced Config.Version; configs without one (version 0) have the same fields.
+const ConfigVersion = 1
+
+// configMigrations[v] upgrades a config from version v to v+1
+var configMigrations = []func(c *Config) error{
+	0: func(c *Config) error { return nil },
+}
+
+// MigrateConfig upgrades a Config persisted by an older release to ConfigVersion,
+// one version at a time. Configs from a newer release are rejected with an error
+// wrapping ErrInvalidOption, since their fields cannot be interpreted.
+func MigrateConfig(old Config) (Config, error) {
+	if old.Version > ConfigVersion {
+		return Config{}, invalidOption("config version %d is newer than supported version %d", old.Version, ConfigVersion)
+	}
+	if old.Version < 0 {
+		return Config{}, invalidOption("config version must not be negative, got %d", old.Version)
+	}
+
+	c := *old.Clone()
+	for c.Version < ConfigVersion {
+		if err := configMigrations[c.Version](&c); err != nil {
+			return Config{}, err
+		}
+		c.Version++
+	}
+	return c, nil
+}
+
+// ConfigToOptions converts c into options that reproduce it: applying them to an empty
+// Config yields a copy of c, so options → Config → options round-trips. Each set
+// (non-zero) field becomes one option. DriverName and Version are skipped, as they
+// are filled in by ExecuteContext and newConfig.
+func ConfigToOptions(c Config) []ConfigOption {
+	snapshot := c.Clone()
+	fields := reflect.ValueOf(snapshot).Elem()
+
+	var options []ConfigOption
+	for i := 0; i < fields.NumField(); i++ {
+		name := fields.Type().Field(i).Name
+		if name == "DriverName" || name == "Version" || fields.Field(i).IsZero() {
+			continue
+		}
+		// Clone again on every use so configs built from the options share no slices or maps
+		options = append(options, func(dst *Config) {
+			reflect.ValueOf(dst).Elem().Field(i).Set(reflect.ValueOf(snapshot.Clone()).Elem().Field(i))
+		})
+	}
+	return options
+}
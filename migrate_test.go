@@ -0,0 +1,67 @@
+package sqlxtx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConfigToOptions_RoundTrip(t *testing.T) {
+	original := &Config{}
+	for _, opt := range []ConfigOption{
+		WithSerializable(),
+		WithTimeout(time.Second),
+		WithMaxRetries(3),
+		WithSearchPath("tenant_a", "public"),
+		WithSessionVariables(map[string]string{"app.user_id": "42"}),
+		WithPGExtensionRequired("pgcrypto"),
+	} {
+		opt(original)
+	}
+
+	rebuilt := &Config{}
+	for _, opt := range ConfigToOptions(*original) {
+		opt(rebuilt)
+	}
+
+	if !reflect.DeepEqual(original, rebuilt) {
+		t.Errorf("expected the rebuilt config to equal the original\noriginal: %+v\nrebuilt:  %+v", original, rebuilt)
+	}
+
+	rebuilt.SearchPath[0] = "tenant_b"
+	rebuilt.SessionVariables["app.user_id"] = "7"
+	rebuilt.TxOptions.ReadOnly = true
+	if original.SearchPath[0] != "tenant_a" || original.SessionVariables["app.user_id"] != "42" || original.TxOptions.ReadOnly {
+		t.Error("expected the rebuilt config not to share state with the original")
+	}
+}
+
+func TestConfigToOptions_SkipsZeroFields(t *testing.T) {
+	if options := ConfigToOptions(Config{DriverName: "postgres", Version: ConfigVersion}); len(options) != 0 {
+		t.Errorf("expected no options, got %d", len(options))
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	migrated, err := MigrateConfig(Config{Timeout: time.Second, SearchPath: []string{"tenant_a"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if migrated.Version != ConfigVersion {
+		t.Errorf("expected version %d, got %d", ConfigVersion, migrated.Version)
+	}
+	if migrated.Timeout != time.Second || len(migrated.SearchPath) != 1 {
+		t.Errorf("expected the settings to be kept, got %+v", migrated)
+	}
+
+	if newConfig(nil).Version != ConfigVersion {
+		t.Error("expected configs built from options to carry the current version")
+	}
+}
+
+func TestMigrateConfig_RejectsNewerVersion(t *testing.T) {
+	if _, err := MigrateConfig(Config{Version: ConfigVersion + 1}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlCharsetPattern matches MySQL character set names such as utf8mb4
+var mysqlCharsetPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// mysqlTimezonePattern matches time zone offsets and names such as +00:00 or Europe/Berlin
+var mysqlTimezonePattern = regexp.MustCompile(`^[A-Za-z0-9_/+:-]+$`)
+
+// WithMySQLDeallocate runs DEALLOCATE PREPARE stmtName after BEGIN (MySQL only).
+// Multiple calls stack.
+func WithMySQLDeallocate(stmtName string) ConfigOption {
+	return func(c *Config) {
+		c.MySQLDeallocate = append(c.MySQLDeallocate, stmtName)
+	}
+}
+
+// WithMySQLCharset runs SET NAMES charset after BEGIN (MySQL only). MySQL has no
+// transaction-scoped variant, so the setting stays on the pooled connection afterwards.
+func WithMySQLCharset(charset string) ConfigOption {
+	return func(c *Config) {
+		c.MySQLCharset = charset
+	}
+}
+
+// WithMySQLTimezone runs SET time_zone = 'tz' after BEGIN (MySQL only). Like
+// WithMySQLCharset, the setting stays on the pooled connection afterwards.
+func WithMySQLTimezone(tz string) ConfigOption {
+	return func(c *Config) {
+		c.MySQLTimezone = tz
+	}
+}
+
+// prepareMySQL applies the MySQL session settings held in config
+func prepareMySQL(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	for _, name := range config.MySQLDeallocate {
+		if _, err := tx.ExecContext(ctx, "DEALLOCATE PREPARE "+name); err != nil {
+			return fmt.Errorf("failed to deallocate prepared statement %s: %w", name, err)
+		}
+	}
+
+	if config.MySQLCharset != "" {
+		if _, err := tx.ExecContext(ctx, "SET NAMES "+config.MySQLCharset); err != nil {
+			return fmt.Errorf("failed to set charset %s: %w", config.MySQLCharset, err)
+		}
+	}
+
+	if config.MySQLTimezone != "" {
+		if _, err := tx.ExecContext(ctx, "SET time_zone = '"+config.MySQLTimezone+"'"); err != nil {
+			return fmt.Errorf("failed to set time zone %s: %w", config.MySQLTimezone, err)
+		}
+	}
+	return nil
+}
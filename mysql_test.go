@@ -0,0 +1,138 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_MySQLDeallocate(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DEALLOCATE PREPARE stmt1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DEALLOCATE PREPARE stmt2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithMySQLDeallocate("stmt1"), WithMySQLDeallocate("stmt2"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_DeallocateAllOnMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithDeallocateAll())
+
+	if !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrDriverNotSupported, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckDriverSupport(t *testing.T) {
+	cases := []struct {
+		driver  string
+		option  ConfigOption
+		wantErr bool
+	}{
+		{"postgres", WithDeallocateAll(), false},
+		{"mysql", WithDeallocateAll(), true},
+		{"sqlite3", WithDeallocateAll(), true},
+		{"mysql", WithMySQLDeallocate("s"), false},
+		{"postgres", WithMySQLDeallocate("s"), true},
+		{"unknown", WithMySQLDeallocate("s"), false},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		c.option(config)
+
+		err := checkDriverSupport(c.driver, config)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: expected error %v, got %v", c.driver, c.wantErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrDriverNotSupported) {
+			t.Errorf("%s: expected ErrDriverNotSupported, got %v", c.driver, err)
+		}
+	}
+}
+
+func TestExecuteContext_MySQLCharsetAndTimezone(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET NAMES utf8mb4").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET time_zone = 'Europe/Berlin'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithMySQLCharset("utf8mb4"), WithMySQLTimezone("Europe/Berlin"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_MySQLSessionOptionsValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	txFunc := func(tx *sqlx.Tx) (any, error) { return nil, nil }
+
+	_, err = ExecuteContext(context.Background(), sqlx.NewDb(db, "postgres"), txFunc, WithMySQLCharset("utf8mb4"))
+	if !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrDriverNotSupported, got %v", err)
+	}
+
+	_, err = ExecuteContext(context.Background(), sqlx.NewDb(db, "mysql"), txFunc, WithMySQLTimezone("UTC'; DROP TABLE users; --"))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,41 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NamedExecContext prepares query with named parameters such as :name inside tx,
+// executes it with arg (a struct or map) and closes the statement
+func NamedExecContext(ctx context.Context, tx *sqlx.Tx, query string, arg any) (sql.Result, error) {
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %q: %w", query, err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exec %q: %w", query, err)
+	}
+	return res, nil
+}
+
+// NamedQueryContext prepares query with named parameters inside tx, runs it with arg
+// and collects the results into a []T like CollectRows
+func NamedQueryContext[T any](ctx context.Context, tx *sqlx.Tx, query string, arg any) ([]T, error) {
+	stmt, err := tx.PrepareNamedContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %q: %w", query, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryxContext(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %q: %w", query, err)
+	}
+	return CollectRows[T](rows)
+}
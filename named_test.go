@@ -0,0 +1,81 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestNamedExecAndQueryContext(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	insert := mock.ExpectPrepare("INSERT INTO users (id, name) VALUES ($1, $2)")
+	insert.ExpectExec().WithArgs(1, "John").WillReturnResult(sqlmock.NewResult(1, 1))
+	insert.WillBeClosed()
+	query := mock.ExpectPrepare("SELECT id, name FROM users WHERE name = $1")
+	query.ExpectQuery().WithArgs("John").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John"))
+	query.WillBeClosed()
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	users, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) ([]rowUser, error) {
+		if _, err := NamedExecContext(ctx, tx, "INSERT INTO users (id, name) VALUES (:id, :name)", rowUser{ID: 1, Name: "John"}); err != nil {
+			return nil, err
+		}
+		return NamedQueryContext[rowUser](ctx, tx, "SELECT id, name FROM users WHERE name = :name", map[string]any{"name": "John"})
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if len(users) != 1 || users[0].Name != "John" {
+		t.Errorf("expected one user named John, got %v", users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestNamedExecContext_ErrorIncludesQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	prepErr := errors.New("syntax error")
+	mock.ExpectBegin()
+	mock.ExpectPrepare("DELETE FROM user").WillReturnError(prepErr)
+	mock.ExpectRollback()
+
+	query := "DELETE FROM user WHERE id = :id"
+	err = ExecuteVoidContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) error {
+		_, err := NamedExecContext(context.Background(), tx, query, map[string]any{"id": 1})
+		return err
+	})
+
+	if !errors.Is(err, prepErr) {
+		t.Errorf("expected prepare error, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), query) {
+		t.Errorf("expected error to include the query, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,73 @@
+package sqlxtx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// savepointNamePattern restricts savepoint names to plain SQL identifiers
+var savepointNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithSavepointName overrides the auto-generated savepoint name used by ExecuteNested
+func WithSavepointName(name string) ConfigOption {
+	return func(c *Config) {
+		c.SavepointName = name
+	}
+}
+
+// ExecuteNested runs a function inside a savepoint of an existing transaction.
+// The savepoint is released on success and rolled back to on failure, leaving
+// the outer transaction usable either way.
+func ExecuteNested[T any](ctx context.Context, tx *sqlx.Tx, fn TxFunc[T], options ...ConfigOption) (result T, err error) {
+	config := &Config{}
+	for _, option := range options {
+		option(config)
+	}
+
+	name := config.SavepointName
+	if name == "" {
+		name, err = generateSavepointName()
+		if err != nil {
+			return result, err
+		}
+	}
+	if !savepointNamePattern.MatchString(name) {
+		return result, fmt.Errorf("invalid savepoint name %q", name)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return result, fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		} else if err != nil {
+			if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+				err = fmt.Errorf("rollback to savepoint %s failed: %v (original error: %w)", name, rollbackErr, err)
+			}
+		} else {
+			if _, releaseErr := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); releaseErr != nil {
+				err = fmt.Errorf("failed to release savepoint %s: %w", name, releaseErr)
+			}
+		}
+	}()
+
+	result, err = fn(tx)
+	return result, err
+}
+
+// generateSavepointName returns a random name of the form sp_<hex>
+func generateSavepointName() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate savepoint name: %w", err)
+	}
+	return "sp_" + hex.EncodeToString(b), nil
+}
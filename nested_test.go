@@ -0,0 +1,107 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteNested_ReleaseOnSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT inner_op").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT inner_op").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	result, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return ExecuteNested(ctx, tx, func(tx *sqlx.Tx) (int, error) {
+			return 7, nil
+		}, WithSavepointName("inner_op"))
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if result != 7 {
+		t.Errorf("expected result to be 7, got %v", result)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteNested_RollbackToSavepointKeepsOuterTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_[0-9a-f]{8}").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_[0-9a-f]{8}").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	nestedErr := errors.New("nested failure")
+	ctx := context.Background()
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		_, err := ExecuteNested(ctx, tx, func(tx *sqlx.Tx) (any, error) {
+			return nil, nestedErr
+		})
+		if !errors.Is(err, nestedErr) {
+			t.Errorf("expected nested error, got %v", err)
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteNested_InvalidSavepointName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	tx, err := sqlxDB.Beginx()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	called := false
+	_, err = ExecuteNested(context.Background(), tx, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithSavepointName("bad; DROP TABLE users"))
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+	if called {
+		t.Error("expected nested function not to be called")
+	}
+}
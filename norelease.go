@@ -0,0 +1,6 @@
+//go:build !RELEASE_BUILD
+
+package sqlxtx
+
+// releaseBuild is false unless the package is built with the RELEASE_BUILD tag
+const releaseBuild = false
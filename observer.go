@@ -0,0 +1,60 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxInfo describes a transaction attempt to observers
+type TxInfo struct {
+	DriverName string
+	TxOptions  *sql.TxOptions
+}
+
+// ReadOnly reports whether the transaction was requested as read-only
+func (i TxInfo) ReadOnly() bool {
+	return i.TxOptions != nil && i.TxOptions.ReadOnly
+}
+
+// IsolationLevel returns the requested isolation level, or sql.LevelDefault
+func (i TxInfo) IsolationLevel() sql.IsolationLevel {
+	if i.TxOptions == nil {
+		return sql.LevelDefault
+	}
+	return i.TxOptions.Isolation
+}
+
+// Observer is notified before a transaction attempt begins. It may return a derived
+// context, which is used to begin the transaction, and a finish function that is
+// called once the attempt has ended. committed reports whether the transaction was
+// committed; err is the attempt's final error, which may be non-nil even after a
+// commit, e.g. when a commit hook or post-commit action fails or WithOnError chose
+// to commit anyway.
+type Observer func(ctx context.Context, info TxInfo) (context.Context, func(committed bool, err error))
+
+// WithObserver registers an observer for every transaction attempt.
+// It is the extension point used by the tracing and metrics sub-packages.
+func WithObserver(observer Observer) ConfigOption {
+	return func(c *Config) {
+		c.Observers = append(c.Observers, observer)
+	}
+}
+
+// startObservers notifies every observer and returns the derived context together
+// with a function that finishes the observers in reverse order
+func startObservers(ctx context.Context, info TxInfo, config *Config) (context.Context, func(committed bool, err error)) {
+	finishers := make([]func(committed bool, err error), 0, len(config.Observers))
+	for _, observer := range config.Observers {
+		var finish func(committed bool, err error)
+		ctx, finish = observer(ctx, info)
+		if finish != nil {
+			finishers = append(finishers, finish)
+		}
+	}
+
+	return ctx, func(committed bool, err error) {
+		for i := len(finishers) - 1; i >= 0; i-- {
+			finishers[i](committed, err)
+		}
+	}
+}
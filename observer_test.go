@@ -0,0 +1,123 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_ObserverFinishedOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	var info TxInfo
+	var finishErr error
+	observer := func(ctx context.Context, i TxInfo) (context.Context, func(committed bool, err error)) {
+		info = i
+		return ctx, func(committed bool, err error) { finishErr = err }
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic, but function did not panic")
+		}
+		if info.DriverName != "postgres" {
+			t.Errorf("expected driver name postgres, got %q", info.DriverName)
+		}
+		if finishErr == nil {
+			t.Error("expected observer to be finished with the panic error")
+		}
+	}()
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		panic("test panic")
+	}, WithObserver(observer))
+}
+
+func TestExecuteContext_ObserverCommittedDespiteAfterCommitFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		option ConfigOption
+	}{
+		{"failing commit hook", WithOnCommit(func() { panic("hook failed") })},
+		{"failing post-commit action", WithPostCommitAction(func(ctx context.Context) error {
+			return errors.New("action failed")
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer db.Close()
+
+			sqlxDB := sqlx.NewDb(db, "postgres")
+
+			mock.ExpectBegin()
+			mock.ExpectCommit()
+
+			var committed bool
+			var finishErr error
+			observer := func(ctx context.Context, i TxInfo) (context.Context, func(committed bool, err error)) {
+				return ctx, func(c bool, err error) { committed, finishErr = c, err }
+			}
+
+			_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+				return nil, nil
+			}, WithObserver(observer), tt.option)
+
+			if err == nil {
+				t.Fatal("expected the after-commit failure to be returned")
+			}
+			if !committed {
+				t.Error("expected the observer to be told the transaction committed")
+			}
+			if finishErr == nil {
+				t.Error("expected the observer to receive the after-commit failure")
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestExecuteContext_ObserverNotCommittedOnRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	committed := true
+	observer := func(ctx context.Context, i TxInfo) (context.Context, func(committed bool, err error)) {
+		return ctx, func(c bool, err error) { committed = c }
+	}
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, errors.New("boom")
+	}, WithObserver(observer))
+
+	if committed {
+		t.Error("expected the observer to be told the transaction rolled back")
+	}
+}
@@ -0,0 +1,73 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBOption configures the connection pool of a database opened with OpenDB
+type DBOption func(db *sqlx.DB)
+
+// WithMaxOpenConns limits the number of open connections, see sql.DB.SetMaxOpenConns
+func WithMaxOpenConns(n int) DBOption {
+	return func(db *sqlx.DB) {
+		db.SetMaxOpenConns(n)
+	}
+}
+
+// WithMaxIdleConns limits the number of idle connections, see sql.DB.SetMaxIdleConns
+func WithMaxIdleConns(n int) DBOption {
+	return func(db *sqlx.DB) {
+		db.SetMaxIdleConns(n)
+	}
+}
+
+// WithConnMaxLifetime closes connections older than d, see sql.DB.SetConnMaxLifetime
+func WithConnMaxLifetime(d time.Duration) DBOption {
+	return func(db *sqlx.DB) {
+		db.SetConnMaxLifetime(d)
+	}
+}
+
+// WithConnMaxIdleTime closes connections idle for longer than d, see sql.DB.SetConnMaxIdleTime
+func WithConnMaxIdleTime(d time.Duration) DBOption {
+	return func(db *sqlx.DB) {
+		db.SetConnMaxIdleTime(d)
+	}
+}
+
+// OpenDB opens a database with sqlx.Open, applies the pool options and pings it, so
+// a bad DSN or unreachable server is reported here rather than by the first
+// transaction. The database is closed again if the ping fails.
+func OpenDB(driverName, dsn string, options ...DBOption) (*sqlx.DB, error) {
+	return OpenDBContext(context.Background(), driverName, dsn, options...)
+}
+
+// OpenDBContext is OpenDB with a context bounding the ping
+func OpenDBContext(ctx context.Context, driverName, dsn string, options ...DBOption) (*sqlx.DB, error) {
+	db, err := sqlx.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	for _, opt := range options {
+		opt(db)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+// MustOpenDB is like OpenDB but panics if the database cannot be opened
+func MustOpenDB(driverName, dsn string, options ...DBOption) *sqlx.DB {
+	db, err := OpenDB(driverName, dsn, options...)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
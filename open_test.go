@@ -0,0 +1,59 @@
+package sqlxtx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOpenDB(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("open_db_ok", sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	mock.ExpectPing()
+
+	db, err := OpenDB("sqlmock", "open_db_ok", WithMaxOpenConns(3), WithMaxIdleConns(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	if got := db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("expected max open connections to be 3, got %d", got)
+	}
+	if db.DriverName() != "sqlmock" {
+		t.Errorf("expected driver name sqlmock, got %q", db.DriverName())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestOpenDB_PingFailure(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("open_db_ping_failure", sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+
+	pingErr := errors.New("connection refused")
+	mock.ExpectPing().WillReturnError(pingErr)
+
+	if _, err := OpenDB("sqlmock", "open_db_ping_failure"); !errors.Is(err, pingErr) {
+		t.Errorf("expected the ping error, got %v", err)
+	}
+}
+
+func TestMustOpenDB_PanicsOnUnknownDriver(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustOpenDB to panic")
+		}
+	}()
+	MustOpenDB("no-such-driver", "")
+}
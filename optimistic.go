@@ -0,0 +1,81 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WithOptimisticLocking retries the whole transaction when it fails with
+// ErrVersionConflict, so it re-reads the row and reapplies its change. Unless
+// retries are configured with WithRetry or WithMaxRetries, up to 2 retries are made.
+func WithOptimisticLocking() ConfigOption {
+	return func(c *Config) {
+		c.OptimisticLocking = true
+		if c.MaxAttempts == 0 {
+			c.MaxAttempts = 3
+		}
+	}
+}
+
+// OptimisticUpdate updates the row of table identified by data's pkCol field, setting
+// every other column mapped by its db tags and incrementing versionCol, but only if
+// the row's version still equals data's versionCol field. If no row matches,
+// ErrVersionConflict is returned. When data is a pointer, its version field is
+// incremented after a successful update.
+func OptimisticUpdate(ctx context.Context, tx *sqlx.Tx, table string, data any, pkCol, versionCol string) error {
+	if err := validateIdentifier("table", table); err != nil {
+		return err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(data))
+	columns, err := structColumns(value.Type())
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	var hasPK, hasVersion bool
+	for _, column := range columns {
+		switch column {
+		case pkCol:
+			hasPK = true
+		case versionCol:
+			hasVersion = true
+		default:
+			sets = append(sets, fmt.Sprintf("%s = :%s", column, column))
+		}
+	}
+	if !hasPK || !hasVersion {
+		return fmt.Errorf("%T must map both %q and %q", data, pkCol, versionCol)
+	}
+	sets = append(sets, fmt.Sprintf("%s = %s + 1", versionCol, versionCol))
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = :%s AND %s = :%s",
+		table, strings.Join(sets, ", "), pkCol, pkCol, versionCol, versionCol)
+	res, err := tx.NamedExecContext(ctx, query, data)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", table, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s row was modified concurrently", ErrVersionConflict, table)
+	}
+
+	if version := tx.Mapper.FieldByName(value, versionCol); version.CanSet() {
+		switch version.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			version.SetInt(version.Int() + 1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			version.SetUint(version.Uint() + 1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type versionedAccount struct {
+	ID      int   `db:"id"`
+	Balance int64 `db:"balance"`
+	Version int   `db:"version"`
+}
+
+func TestOptimisticUpdate_IncrementsVersion(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts SET balance = $1, version = version + 1 WHERE id = $2 AND version = $3").
+		WithArgs(int64(100), 1, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	account := &versionedAccount{ID: 1, Balance: 100, Version: 4}
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		return OptimisticUpdate(ctx, tx, "accounts", account, "id", "version")
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if account.Version != 5 {
+		t.Errorf("expected version 5, got %d", account.Version)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithOptimisticLocking_RetriesVersionConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	attempts := 0
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		attempts++
+		return OptimisticUpdate(ctx, tx, "accounts", versionedAccount{ID: 1, Version: 4}, "id", "version")
+	}, WithOptimisticLocking())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestOptimisticUpdate_ConflictWithoutRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE accounts").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		return OptimisticUpdate(ctx, tx, "accounts", versionedAccount{ID: 1, Version: 4}, "id", "version")
+	})
+
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
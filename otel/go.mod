@@ -0,0 +1,27 @@
+module github.com/huangc28/sqlx-tx/otel
+
+go 1.24.3
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/huangc28/sqlx-tx v0.0.0-20261014084952-4ea17f2809d0
+	github.com/jmoiron/sqlx v1.4.0
+	go.opentelemetry.io/otel v1.41.0
+	go.opentelemetry.io/otel/sdk v1.41.0
+	go.opentelemetry.io/otel/trace v1.41.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel/metric v1.41.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/time v0.12.0 // indirect
+)
+
+// Builds inside this repository use the parent module; replace directives are
+// ignored for dependents, which resolve the version required above.
+replace github.com/huangc28/sqlx-tx => ../
@@ -0,0 +1,56 @@
+// Package otel adds OpenTelemetry tracing to sqlxtx transactions.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+// spanName is the name of the span created for each transaction attempt
+const spanName = "sqlxtx.transaction"
+
+// WithTracing creates a span around every transaction attempt. The span starts
+// before BEGIN and ends once the transaction has been committed or rolled back.
+// Errors after a successful commit are recorded on the span without marking it failed.
+func WithTracing(tracer trace.Tracer) sqlxtx.ConfigOption {
+	return sqlxtx.WithObserver(func(ctx context.Context, info sqlxtx.TxInfo) (context.Context, func(committed bool, err error)) {
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", dbSystem(info.DriverName)),
+				attribute.Bool("db.transaction.readonly", info.ReadOnly()),
+				attribute.String("db.transaction.isolation_level", info.IsolationLevel().String()),
+			),
+		)
+
+		return ctx, func(committed bool, err error) {
+			span.SetAttributes(attribute.Bool("db.transaction.committed", committed))
+			if err != nil {
+				span.RecordError(err)
+			}
+			if !committed && err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	})
+}
+
+// dbSystem maps a database/sql driver name to the OpenTelemetry db.system value
+func dbSystem(driverName string) string {
+	switch driverName {
+	case "postgres", "pgx", "pq":
+		return "postgresql"
+	case "sqlite3", "sqlite":
+		return "sqlite"
+	case "sqlserver", "mssql":
+		return "mssql"
+	default:
+		return driverName
+	}
+}
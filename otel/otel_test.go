@@ -0,0 +1,62 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestWithTracing_RecordsSpan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	txErr := errors.New("test error")
+	_, err = sqlxtx.ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	}, sqlxtx.WithReadOnly(), WithTracing(provider.Tracer("test")))
+
+	if !errors.Is(err, txErr) {
+		t.Fatalf("expected test error, got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 ended span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected error status, got %v", span.Status().Code)
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if got := attrs["db.system"].AsString(); got != "postgresql" {
+		t.Errorf("expected db.system postgresql, got %q", got)
+	}
+	if !attrs["db.transaction.readonly"].AsBool() {
+		t.Error("expected db.transaction.readonly to be true")
+	}
+}
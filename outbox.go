@@ -0,0 +1,46 @@
+package sqlxtx
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WithOutboxFallback makes ExecuteWithOutbox hand a failed outbox call to fn instead of
+// returning the error, for fire-and-forget publishing. T must match the result type
+// of the ExecuteWithOutbox call, otherwise it fails with ErrInvalidOption, as do the
+// other Execute functions given this option.
+func WithOutboxFallback[T any](fn func(result T, err error)) ConfigOption {
+	return func(c *Config) {
+		c.OutboxFallback = fn
+	}
+}
+
+// ExecuteWithOutbox runs fn within a transaction and calls outboxFn with its result
+// once the transaction has committed. outboxFn is not called when the transaction
+// fails. Since the transaction is already committed, an outboxFn error is returned
+// as-is, or passed to the WithOutboxFallback handler if one is configured.
+func ExecuteWithOutbox[T any](ctx context.Context, db Executer, fn TxFunc[T], outboxFn func(T) error, options ...ConfigOption) (T, error) {
+	var fallback func(T, error)
+	result, err := execute(ctx, db, entryOutbox, func(config *Config) (ContextTxFunc[T], error) {
+		if f := config.OutboxFallback; f != nil {
+			var ok bool
+			if fallback, ok = f.(func(T, error)); !ok {
+				var zero T
+				return nil, invalidOption("outbox fallback %T does not match result type %T", f, zero)
+			}
+		}
+		return func(_ context.Context, tx *sqlx.Tx) (T, error) { return fn(tx) }, nil
+	}, options)
+	if err != nil {
+		return result, err
+	}
+
+	if err := outboxFn(result); err != nil {
+		if fallback == nil {
+			return result, err
+		}
+		fallback(result, err)
+	}
+	return result, nil
+}
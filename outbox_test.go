@@ -0,0 +1,191 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteWithOutbox_PublishesAfterCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	committed := false
+	var published int
+	result, err := ExecuteWithOutbox(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 42, nil
+	}, func(id int) error {
+		if !committed {
+			t.Error("expected outbox to run after commit")
+		}
+		published = id
+		return nil
+	}, WithOnCommit(func() { committed = true }))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if result != 42 || published != 42 {
+		t.Errorf("expected result and published value to be 42, got %d and %d", result, published)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithOutbox_SkipsOnRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	published := false
+	_, err = ExecuteWithOutbox(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 0, txErr
+	}, func(int) error {
+		published = true
+		return nil
+	})
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if published {
+		t.Error("expected outbox not to run after rollback")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithOutbox_ErrorAndFallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	publishErr := errors.New("broker unavailable")
+	txFunc := func(tx *sqlx.Tx) (int, error) { return 7, nil }
+	outboxFn := func(int) error { return publishErr }
+
+	_, err = ExecuteWithOutbox(context.Background(), sqlxDB, txFunc, outboxFn)
+	if !errors.Is(err, publishErr) {
+		t.Errorf("expected publish error, got %v", err)
+	}
+
+	var gotResult int
+	var gotErr error
+	_, err = ExecuteWithOutbox(context.Background(), sqlxDB, txFunc, outboxFn, WithOutboxFallback(func(result int, err error) {
+		gotResult, gotErr = result, err
+	}))
+	if err != nil {
+		t.Errorf("expected fallback to swallow the error, got %v", err)
+	}
+	if gotResult != 7 || !errors.Is(gotErr, publishErr) {
+		t.Errorf("expected fallback to receive 7 and publish error, got %d and %v", gotResult, gotErr)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithOutbox_FallbackTypeMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ExecuteWithOutbox(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 0, nil
+	}, func(int) error { return nil }, WithOutboxFallback(func(string, error) {}))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no transaction to begin: %s", err)
+	}
+}
+
+func TestExecuteWithOutbox_AppliesOptionsOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	applied := 0
+	countOption := func(c *Config) { applied++ }
+	_, err = ExecuteWithOutbox(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 1, nil
+	}, func(int) error { return nil }, countOption, WithOutboxFallback(func(int, error) {}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected options to be applied once, got %d", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithOutboxFallback_RejectedOutsideExecuteWithOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		t.Error("expected the transaction function not to run")
+		return 0, nil
+	}, WithOutboxFallback(func(int, error) {}))
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,50 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrConnectionTimeout is returned, wrapped in a BeginError, when no pooled connection
+// became available within WithMaxConnectionWait. It wraps context.DeadlineExceeded.
+var ErrConnectionTimeout = fmt.Errorf("timed out waiting for a database connection: %w", context.DeadlineExceeded)
+
+// WithMaxConnectionWait bounds how long BEGIN may wait for a connection from the pool,
+// without shortening the deadline of the transaction itself
+func WithMaxConnectionWait(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxConnectionWait = d
+	}
+}
+
+// beginTx begins a transaction, giving up with ErrConnectionTimeout if that takes
+// longer than config.MaxConnectionWait. database/sql rolls a transaction back when
+// the context passed to BeginTx is cancelled, so instead of a deadline the wait is
+// bounded by a timer that is stopped once BEGIN returns. The returned release
+// function must be called after the transaction has ended.
+func beginTx(ctx context.Context, db Executer, config *Config) (*sqlx.Tx, func(), error) {
+	if config.MaxConnectionWait <= 0 {
+		tx, err := begin(ctx, db, config)
+		return tx, func() {}, err
+	}
+
+	beginCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(config.MaxConnectionWait, cancel)
+
+	tx, err := begin(beginCtx, db, config)
+	if !timer.Stop() {
+		if err == nil {
+			_ = tx.Rollback()
+		}
+		cancel()
+		return nil, func() {}, ErrConnectionTimeout
+	}
+	if err != nil {
+		cancel()
+		return nil, func() {}, err
+	}
+	return tx, cancel, nil
+}
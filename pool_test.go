@@ -0,0 +1,69 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_MaxConnectionWaitTimesOut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin().WillDelayFor(time.Second)
+
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithMaxConnectionWait(10*time.Millisecond))
+
+	if !errors.Is(err, ErrConnectionTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrConnectionTimeout, got %v", err)
+	}
+	if !IsBeginError(err) {
+		t.Errorf("expected a BeginError, got %v", err)
+	}
+	if called {
+		t.Error("expected the transaction function not to be called")
+	}
+}
+
+func TestExecuteContext_MaxConnectionWaitKeepsTransactionContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		// Outlive the connection wait: the transaction must not be cancelled
+		time.Sleep(30 * time.Millisecond)
+		_, err := tx.ExecContext(ctx, "UPDATE users SET active = true")
+		return nil, err
+	}, WithMaxConnectionWait(10*time.Millisecond))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,453 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// schemaNamePattern restricts search_path entries to plain unquoted identifiers
+var schemaNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// memorySizePattern matches PostgreSQL memory sizes (GUC_UNIT_MEMORY) such as 256MB,
+// and the equivalent binary unit spelling such as 256MiB
+var memorySizePattern = regexp.MustCompile(`^(\d+)(B|kB|MB|GB|TB|KiB|MiB|GiB|TiB)?$`)
+
+// WithStatementTimeout runs SET LOCAL statement_timeout after BEGIN, which aborts any
+// statement of the transaction whose total execution time, lock waits included,
+// exceeds d (PostgreSQL only)
+func WithStatementTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.StatementTimeout = d
+	}
+}
+
+// WithLockTimeout runs SET LOCAL lock_timeout after BEGIN, which aborts any statement
+// of the transaction that waits longer than d to acquire a single lock, however long
+// the statement itself runs (PostgreSQL only)
+func WithLockTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.LockTimeout = d
+	}
+}
+
+// WithIdleInTransactionTimeout runs SET LOCAL idle_in_transaction_session_timeout
+// after BEGIN, so the server terminates the session if the transaction sits idle for
+// longer than d, e.g. because the caller hangs, instead of holding back vacuum
+// (PostgreSQL 9.6+ only). Older servers and other drivers fail with
+// ErrFeatureNotSupported.
+func WithIdleInTransactionTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.IdleInTransactionTimeout = d
+	}
+}
+
+// WithPGLockWaitTimeout is WithLockTimeout under a name that spells out the
+// lock-wait semantics, e.g. WithPGLockWaitTimeout(500*time.Millisecond) to stop lock
+// queues from piling up (PostgreSQL only)
+func WithPGLockWaitTimeout(d time.Duration) ConfigOption {
+	return WithLockTimeout(d)
+}
+
+// WithAdvisoryLock takes pg_advisory_xact_lock(key) after BEGIN, blocking until the
+// lock is granted. The lock is released automatically when the transaction ends.
+func WithAdvisoryLock(key int64) ConfigOption {
+	return func(c *Config) {
+		c.AdvisoryLocks = append(c.AdvisoryLocks, key)
+	}
+}
+
+// WithAdvisoryTryLock takes pg_try_advisory_xact_lock(key) after BEGIN. If the lock
+// is not immediately available the transaction is rolled back with ErrLockNotAcquired.
+func WithAdvisoryTryLock(key int64) ConfigOption {
+	return func(c *Config) {
+		c.AdvisoryTryLocks = append(c.AdvisoryTryLocks, key)
+	}
+}
+
+// WithSearchPath runs SET LOCAL search_path after BEGIN so unqualified names resolve
+// against the given schemas, in order (PostgreSQL only). Schema names must be plain
+// identifiers; anything else is rejected by Config.Validate.
+func WithSearchPath(schemas ...string) ConfigOption {
+	return func(c *Config) {
+		c.SearchPath = append(c.SearchPath, schemas...)
+	}
+}
+
+// WithSessionVariables runs SET LOCAL name = value after BEGIN for every entry in vars
+// (PostgreSQL only). Values are quoted as string literals and names must be plain or
+// dot-qualified identifiers such as work_mem or app.user_id. The order in which the
+// variables are set is not guaranteed.
+func WithSessionVariables(vars map[string]string) ConfigOption {
+	return func(c *Config) {
+		if c.SessionVariables == nil {
+			c.SessionVariables = make(map[string]string, len(vars))
+		}
+		for name, value := range vars {
+			c.SessionVariables[name] = value
+		}
+	}
+}
+
+// WithPGRowLevelSecurity runs SET LOCAL app.<key> = value after BEGIN for every entry
+// in vars, for row-level security policies that read current_setting('app.<key>')
+// (PostgreSQL only). It is WithSessionVariables with the app. prefix added, so the
+// same quoting and validation apply and the settings end with the transaction.
+func WithPGRowLevelSecurity(vars map[string]string) ConfigOption {
+	prefixed := make(map[string]string, len(vars))
+	for key, value := range vars {
+		prefixed["app."+key] = value
+	}
+	return WithSessionVariables(prefixed)
+}
+
+// WithPGCurrentUser sets app.current_user_id to userID for row-level security policies
+// (PostgreSQL only)
+func WithPGCurrentUser(userID int64) ConfigOption {
+	return WithPGRowLevelSecurity(map[string]string{"current_user_id": strconv.FormatInt(userID, 10)})
+}
+
+// WithApplicationName runs SET LOCAL application_name after BEGIN so the transaction
+// is attributed to name in pg_stat_activity and the server logs (PostgreSQL only)
+func WithApplicationName(name string) ConfigOption {
+	return func(c *Config) {
+		c.ApplicationName = name
+	}
+}
+
+// WithPGComment prepends /* comment */ to every query run through an InterceptedTx,
+// making it visible in pg_stat_statements. It requires ExecuteIntercepted; other
+// entry points reject it with ErrInvalidOption. Any "*/" or "/*" in comment is
+// broken up: PostgreSQL comments nest, so either would leave the comment unbalanced.
+func WithPGComment(comment string) ConfigOption {
+	// Replacing "*/" first means the second pass cannot create a new "*/"
+	escaped := strings.ReplaceAll(strings.ReplaceAll(comment, "*/", "* /"), "/*", "/ *")
+	prefix := "/* " + escaped + " */ "
+	return WithQueryInterceptor(func(ctx context.Context, query string) string {
+		return prefix + query
+	})
+}
+
+// WithCorrelationID tags the transaction with a trace or correlation ID: it sets
+// application_name to id like WithApplicationName, and prepends /* cid=<id> */ to
+// every query run through an InterceptedTx so the ID shows up next to each statement
+// in the server logs (PostgreSQL only). The ID is percent-encoded inside the comment.
+// Like WithPGComment it requires ExecuteIntercepted; use WithApplicationName to tag
+// transactions started any other way.
+func WithCorrelationID(id string) ConfigOption {
+	prefix := "/* cid=" + url.QueryEscape(id) + " */ "
+	intercept := WithQueryInterceptor(func(ctx context.Context, query string) string {
+		return prefix + query
+	})
+	return func(c *Config) {
+		c.ApplicationName = id
+		intercept(c)
+	}
+}
+
+// synchronousCommitModes lists the values accepted by WithSynchronousCommit
+var synchronousCommitModes = []string{"on", "off", "local", "remote_write", "remote_apply"}
+
+// WithSynchronousCommit runs SET LOCAL synchronous_commit after BEGIN, trading
+// durability for commit latency (PostgreSQL only). mode must be one of on, off,
+// local, remote_write or remote_apply.
+func WithSynchronousCommit(mode string) ConfigOption {
+	return func(c *Config) {
+		c.SynchronousCommit = mode
+	}
+}
+
+// WithWorkMem runs SET LOCAL work_mem after BEGIN, e.g. "256MB" for large sorts
+// (PostgreSQL only). Binary unit spellings such as "256MiB" are accepted too;
+// a size without unit is in kilobytes.
+func WithWorkMem(size string) ConfigOption {
+	return func(c *Config) {
+		c.WorkMem = size
+	}
+}
+
+// WithPGRole runs SET LOCAL ROLE role after BEGIN, e.g. to apply row-level security
+// policies for an application role (PostgreSQL only). The role is quoted as an
+// identifier. Like every SET LOCAL it is undone by the server when the transaction
+// ends, so no RESET ROLE is needed after a commit or rollback.
+func WithPGRole(role string) ConfigOption {
+	return func(c *Config) {
+		c.Role = role
+	}
+}
+
+// WithDeallocateStatement runs DEALLOCATE name after BEGIN, releasing a single
+// prepared statement instead of all of them (PostgreSQL only). name is quoted, so it
+// must match the name listed by pg_prepared_statements exactly. Multiple calls stack.
+func WithDeallocateStatement(name string) ConfigOption {
+	return func(c *Config) {
+		c.DeallocateNames = append(c.DeallocateNames, name)
+	}
+}
+
+// WithDeallocatePattern deallocates every prepared statement of the session whose
+// name matches the SQL LIKE pattern, as listed by pg_prepared_statements
+// (PostgreSQL only). Multiple calls stack.
+func WithDeallocatePattern(pattern string) ConfigOption {
+	return func(c *Config) {
+		c.DeallocatePatterns = append(c.DeallocatePatterns, pattern)
+	}
+}
+
+// WithPGLogParameters runs SET LOCAL log_parameter_max_length = maxLen (-1 logs
+// parameters in full) and SET LOCAL log_min_duration_statement = 0 after BEGIN, so the
+// server log shows every statement of the transaction with its bind parameters
+// (PostgreSQL 13 or later only). Both settings need superuser rights or a matching
+// GRANT SET. This is a debugging aid and may write sensitive values to the server
+// log. In a RELEASE_BUILD build, the first Config.Validate call that sees it logs a
+// warning through slog's default logger; later calls stay silent.
+func WithPGLogParameters(maxLen int) ConfigOption {
+	return func(c *Config) {
+		c.LogParameters = true
+		c.LogParameterMaxLength = maxLen
+	}
+}
+
+// WithPGExtensionRequired checks pg_extension after BEGIN and fails the transaction
+// with ErrExtensionNotInstalled before fn runs if the extension, e.g. "pgcrypto", is
+// not installed (PostgreSQL only). Multiple calls stack.
+func WithPGExtensionRequired(name string) ConfigOption {
+	return func(c *Config) {
+		c.RequiredExtensions = append(c.RequiredExtensions, name)
+	}
+}
+
+// Notification is a NOTIFY queued by WithNotifyOnCommit
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// WithNotifyOnCommit runs NOTIFY channel, payload as the last statement before
+// COMMIT (PostgreSQL only). The notification is part of the transaction, so
+// listeners only receive it if the transaction commits. Multiple calls stack.
+func WithNotifyOnCommit(channel, payload string) ConfigOption {
+	return func(c *Config) {
+		c.Notifications = append(c.Notifications, Notification{Channel: channel, Payload: payload})
+	}
+}
+
+// WithRefreshMaterializedView runs REFRESH MATERIALIZED VIEW CONCURRENTLY for each
+// view after a successful commit, outside the transaction, on a connection from the
+// database handle (PostgreSQL only). Refreshes run after the WithPostCommitAction
+// actions and their failures are returned the same way, as a PostCommitError.
+// CONCURRENTLY requires a unique index on each view. Multiple calls stack.
+func WithRefreshMaterializedView(viewNames ...string) ConfigOption {
+	return func(c *Config) {
+		c.RefreshViews = append(c.RefreshViews, viewNames...)
+	}
+}
+
+// preparePostgres applies the PostgreSQL session settings held in config
+func preparePostgres(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	for _, name := range config.RequiredExtensions {
+		if err := requireExtension(ctx, tx, name); err != nil {
+			return err
+		}
+	}
+
+	if config.LogParameters {
+		if err := setLocal(ctx, tx, "log_parameter_max_length", strconv.Itoa(config.LogParameterMaxLength)); err != nil {
+			return err
+		}
+		if err := setLocal(ctx, tx, "log_min_duration_statement", "0"); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range config.DeallocateNames {
+		if _, err := tx.ExecContext(ctx, "DEALLOCATE "+quoteIdentifier(name)); err != nil {
+			return fmt.Errorf("failed to deallocate prepared statement %s: %w", name, err)
+		}
+	}
+
+	for _, pattern := range config.DeallocatePatterns {
+		if err := deallocateMatching(ctx, tx, pattern); err != nil {
+			return err
+		}
+	}
+
+	if config.StatementTimeout > 0 {
+		if err := setLocal(ctx, tx, "statement_timeout", formatMillis(config.StatementTimeout)); err != nil {
+			return err
+		}
+	}
+
+	if config.LockTimeout > 0 {
+		if err := setLocal(ctx, tx, "lock_timeout", formatMillis(config.LockTimeout)); err != nil {
+			return err
+		}
+	}
+
+	if config.IdleInTransactionTimeout > 0 {
+		err := setLocal(ctx, tx, "idle_in_transaction_session_timeout", formatMillis(config.IdleInTransactionTimeout))
+		if sqlState(err) == sqlStateUndefinedObject {
+			err = fmt.Errorf("%w: idle_in_transaction_session_timeout needs PostgreSQL 9.6 or later: %w", ErrFeatureNotSupported, err)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.ApplicationName != "" {
+		if err := setLocal(ctx, tx, "application_name", quoteLiteral(config.ApplicationName)); err != nil {
+			return err
+		}
+	}
+
+	if config.Role != "" {
+		if _, err := tx.ExecContext(ctx, "SET LOCAL ROLE "+quoteIdentifier(config.Role)); err != nil {
+			return fmt.Errorf("failed to set role %s: %w", config.Role, err)
+		}
+	}
+
+	if config.SynchronousCommit != "" {
+		if err := setLocal(ctx, tx, "synchronous_commit", config.SynchronousCommit); err != nil {
+			return err
+		}
+	}
+
+	if config.WorkMem != "" {
+		if err := setLocal(ctx, tx, "work_mem", quoteLiteral(pgMemorySize(config.WorkMem))); err != nil {
+			return err
+		}
+	}
+
+	if len(config.SearchPath) > 0 {
+		if err := setLocal(ctx, tx, "search_path", strings.Join(config.SearchPath, ", ")); err != nil {
+			return err
+		}
+	}
+
+	for name, value := range config.SessionVariables {
+		if err := setLocal(ctx, tx, name, quoteLiteral(value)); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range config.AdvisoryLocks {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", key); err != nil {
+			return fmt.Errorf("failed to acquire advisory lock %d: %w", key, err)
+		}
+	}
+
+	for _, key := range config.AdvisoryTryLocks {
+		var acquired bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to try advisory lock %d: %w", key, err)
+		}
+		if !acquired {
+			return fmt.Errorf("%w: advisory lock %d", ErrLockNotAcquired, key)
+		}
+	}
+
+	return nil
+}
+
+// requireExtension returns ErrExtensionNotInstalled unless the named extension is installed
+func requireExtension(ctx context.Context, tx *sqlx.Tx, name string) error {
+	var installed []string
+	if err := tx.SelectContext(ctx, &installed, "SELECT extname FROM pg_extension WHERE extname = $1", name); err != nil {
+		return fmt.Errorf("failed to check extension %s: %w", name, err)
+	}
+	if len(installed) == 0 {
+		return fmt.Errorf("%w: %s", ErrExtensionNotInstalled, name)
+	}
+	return nil
+}
+
+// deallocateMatching deallocates the session's prepared statements whose names match pattern
+func deallocateMatching(ctx context.Context, tx *sqlx.Tx, pattern string) error {
+	var names []string
+	if err := tx.SelectContext(ctx, &names, "SELECT name FROM pg_prepared_statements WHERE name LIKE $1", pattern); err != nil {
+		return fmt.Errorf("failed to list prepared statements matching %q: %w", pattern, err)
+	}
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, "DEALLOCATE "+quoteIdentifier(name)); err != nil {
+			return fmt.Errorf("failed to deallocate prepared statement %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// pgMemorySize rewrites binary unit suffixes (KiB, MiB, ...) to PostgreSQL's kB, MB, ...
+// which use the same 1024 multiplier
+func pgMemorySize(size string) string {
+	if strings.HasSuffix(size, "KiB") {
+		return strings.TrimSuffix(size, "KiB") + "kB"
+	}
+	for _, unit := range []string{"M", "G", "T"} {
+		if strings.HasSuffix(size, unit+"iB") {
+			return strings.TrimSuffix(size, unit+"iB") + unit + "B"
+		}
+	}
+	return size
+}
+
+// setLocal runs SET LOCAL for a trusted setting name and pre-formatted literal value
+func setLocal(ctx context.Context, tx *sqlx.Tx, name, value string) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL %s = %s", name, value)); err != nil {
+		return fmt.Errorf("failed to set %s: %w", name, err)
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a PostgreSQL identifier, doubling embedded double quotes.
+// Like lib/pq's QuoteIdentifier, the name is truncated at the first zero byte.
+func quoteIdentifier(name string) string {
+	if end := strings.IndexByte(name, 0); end >= 0 {
+		name = name[:end]
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a PostgreSQL string literal as lib/pq's QuoteLiteral does: single
+// quotes are doubled, and a literal containing backslashes becomes an E-prefixed escape string
+func quoteLiteral(literal string) string {
+	literal = strings.ReplaceAll(literal, `'`, `''`)
+	if strings.Contains(literal, `\`) {
+		return ` E'` + strings.ReplaceAll(literal, `\`, `\\`) + `'`
+	}
+	return `'` + literal + `'`
+}
+
+// formatMillis renders d as a PostgreSQL millisecond interval literal. d is rounded up
+// to a whole millisecond, since a value truncated to 0ms would disable the timeout.
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("'%dms'", (d+time.Millisecond-1)/time.Millisecond)
+}
+
+// notifyPostgres sends the notifications queued by WithNotifyOnCommit
+func notifyPostgres(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	for _, n := range config.Notifications {
+		query := fmt.Sprintf("NOTIFY %s, %s", quoteIdentifier(n.Channel), quoteLiteral(n.Payload))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to notify channel %s: %w", n.Channel, err)
+		}
+	}
+	return nil
+}
+
+// refreshMaterializedView refreshes view on db outside any transaction
+func refreshMaterializedView(ctx context.Context, db Executer, view string) error {
+	execer, ok := db.(dbExecer)
+	if !ok {
+		return fmt.Errorf("database handle %T cannot refresh materialized view %s outside a transaction", db, view)
+	}
+	if _, err := execer.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+		return fmt.Errorf("failed to refresh materialized view %s: %w", view, err)
+	}
+	return nil
+}
@@ -0,0 +1,775 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestExecuteContext_StatementAndLockTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = '5000ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET LOCAL lock_timeout = '2000ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithStatementTimeout(5*time.Second), WithLockTimeout(2*time.Second))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SubMillisecondTimeoutsRoundUp(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = '1ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET LOCAL lock_timeout = '2ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET LOCAL idle_in_transaction_session_timeout = '1ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithStatementTimeout(500*time.Microsecond), WithLockTimeout(1500*time.Microsecond),
+		WithIdleInTransactionTimeout(time.Nanosecond))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SetLocalFailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	setErr := errors.New("unrecognized configuration parameter")
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout").WillReturnError(setErr)
+	mock.ExpectRollback()
+
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithStatementTimeout(time.Second))
+
+	if !errors.Is(err, setErr) {
+		t.Errorf("expected SET LOCAL error, got %v", err)
+	}
+	if called {
+		t.Error("expected transaction function not to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_AdvisoryLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).WithArgs(int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithAdvisoryLock(42))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_AdvisoryTryLockNotAcquired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT pg_try_advisory_xact_lock\(\$1\)`).WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+	mock.ExpectRollback()
+
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithAdvisoryTryLock(7))
+
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("expected ErrLockNotAcquired, got %v", err)
+	}
+	if called {
+		t.Error("expected transaction function not to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SearchPath(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL search_path = tenant_42, public").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSearchPath("tenant_42", "public"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SearchPathRejectsInvalidSchema(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSearchPath("tenant_42; DROP TABLE users"))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SessionVariables(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL work_mem = '64MB'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET LOCAL app.user_id = 'o''brien'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSessionVariables(map[string]string{"work_mem": "64MB", "app.user_id": "o'brien"}))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SessionVariablesRejectsInvalidName(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSessionVariables(map[string]string{"work_mem = '1GB'; --": "x"}))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_DeallocateStatementAndPattern(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DEALLOCATE "get_user"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DEALLOCATE "list_users"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT name FROM pg_prepared_statements WHERE name LIKE $1").
+		WithArgs("stmtcache_%").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("stmtcache_1").AddRow("stmtcache_2"))
+	mock.ExpectExec(`DEALLOCATE "stmtcache_1"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DEALLOCATE "stmtcache_2"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	},
+		WithDeallocateStatement("get_user"),
+		WithDeallocateStatement("list_users"),
+		WithDeallocatePattern("stmtcache_%"),
+	)
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteIntercepted_ApplicationNameAndComment(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL application_name = 'billing-worker'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("/* job=invoice * / DROP */ DELETE FROM invoices").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	_, err = ExecuteIntercepted(ctx, sqlxDB, func(tx *InterceptedTx) (any, error) {
+		_, err := tx.ExecContext(ctx, "DELETE FROM invoices")
+		return nil, err
+	}, WithApplicationName("billing-worker"), WithPGComment("job=invoice */ DROP"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_SynchronousCommit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL synchronous_commit = off").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithSynchronousCommit("off"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_WorkMem(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL work_mem = '256MB'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithWorkMem("256MiB"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPGMemorySize(t *testing.T) {
+	cases := map[string]string{"64": "64", "512kB": "512kB", "1GB": "1GB", "8KiB": "8kB", "256MiB": "256MB", "2TiB": "2TB"}
+	for in, want := range cases {
+		if got := pgMemorySize(in); got != want {
+			t.Errorf("pgMemorySize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExecuteContext_PGRoleFailureRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	roleErr := errors.New(`role "app_user" does not exist`)
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL ROLE "app_user"`).WillReturnError(roleErr)
+	mock.ExpectRollback()
+
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithPGRole("app_user"))
+
+	if !errors.Is(err, roleErr) {
+		t.Errorf("expected role error, got %v", err)
+	}
+	if called {
+		t.Error("expected transaction function not to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_NotifyOnCommit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders DEFAULT VALUES").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`NOTIFY "order_events", 'it''s created'`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		_, err := tx.Exec("INSERT INTO orders DEFAULT VALUES")
+		return nil, err
+	}, WithNotifyOnCommit("order_events", "it's created"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_NotifySkippedOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	}, WithNotifyOnCommit("order_events", "created"))
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteIntercepted_CorrelationID(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL application_name = 'req-42*/'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("/* cid=req-42%2A%2F */ DELETE FROM invoices").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	_, err = ExecuteIntercepted(ctx, sqlxDB, func(tx *InterceptedTx) (any, error) {
+		_, err := tx.ExecContext(ctx, "DELETE FROM invoices")
+		return nil, err
+	}, WithCorrelationID("req-42*/"))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_PGRowLevelSecurity(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL app.current_user_id = '42'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithPGCurrentUser(42))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithPGRowLevelSecurity_RejectsInvalidKey(t *testing.T) {
+	config := &Config{DriverName: "postgres"}
+	WithPGRowLevelSecurity(map[string]string{"tenant; RESET ALL": "1"})(config)
+
+	if err := config.Validate(); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestExecuteContext_RefreshMaterializedViewAfterCommit(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	refreshErr := errors.New("cannot refresh concurrently")
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectExec("REFRESH MATERIALIZED VIEW CONCURRENTLY daily_totals").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("REFRESH MATERIALIZED VIEW CONCURRENTLY reports.monthly_totals").WillReturnError(refreshErr)
+
+	result, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return 7, nil
+	}, WithRefreshMaterializedView("daily_totals", "reports.monthly_totals"))
+
+	if !IsPostCommitError(err) || !errors.Is(err, refreshErr) {
+		t.Errorf("expected PostCommitError wrapping the refresh error, got %v", err)
+	}
+	if result != 7 {
+		t.Errorf("expected committed result 7, got %d", result)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithPGLockWaitTimeout_RejectedOnMySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "mysql")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithPGLockWaitTimeout(500*time.Millisecond))
+
+	if !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrDriverNotSupported, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_IdleInTransactionTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL idle_in_transaction_session_timeout = '30000ms'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithIdleInTransactionTimeout(30*time.Second))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_IdleInTransactionTimeoutOnOldServer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL idle_in_transaction_session_timeout").
+		WillReturnError(&pq.Error{Code: "42704", Message: `unrecognized configuration parameter "idle_in_transaction_session_timeout"`})
+	mock.ExpectRollback()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithIdleInTransactionTimeout(time.Second))
+
+	if !errors.Is(err, ErrFeatureNotSupported) {
+		t.Errorf("expected ErrFeatureNotSupported, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithIdleInTransactionTimeout_RejectedOnSQLite(t *testing.T) {
+	config := &Config{DriverName: "sqlite3"}
+	WithIdleInTransactionTimeout(time.Second)(config)
+
+	err := config.Validate()
+	if !errors.Is(err, ErrFeatureNotSupported) || !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrFeatureNotSupported and ErrDriverNotSupported, got %v", err)
+	}
+}
+
+func TestExecuteContext_PGExtensionRequired(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT extname FROM pg_extension WHERE extname = $1").
+		WithArgs("pgcrypto").
+		WillReturnRows(sqlmock.NewRows([]string{"extname"}).AddRow("pgcrypto"))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithPGExtensionRequired("pgcrypto"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_PGExtensionMissing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT extname FROM pg_extension").
+		WithArgs("pgcrypto").
+		WillReturnRows(sqlmock.NewRows([]string{"extname"}))
+	mock.ExpectRollback()
+
+	called := false
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		called = true
+		return nil, nil
+	}, WithPGExtensionRequired("pgcrypto"))
+
+	if !errors.Is(err, ErrExtensionNotInstalled) {
+		t.Errorf("expected ErrExtensionNotInstalled, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "pgcrypto") {
+		t.Errorf("expected the error to name the extension, got %v", err)
+	}
+	if called {
+		t.Error("expected the transaction function not to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_PGLogParameters(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL log_parameter_max_length = 512").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SET LOCAL log_min_duration_statement = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithPGLogParameters(512))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithPGLogParameters_InvalidLength(t *testing.T) {
+	config := &Config{}
+	WithPGLogParameters(-2)(config)
+
+	if err := config.Validate(); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestQuoting_MatchesLibPQ(t *testing.T) {
+	for _, s := range []string{"plain", "it's", `back\slash`, `it's a \ mix`, `"quoted"`, "nul\x00tail", ""} {
+		if got, want := quoteLiteral(s), pq.QuoteLiteral(s); got != want {
+			t.Errorf("quoteLiteral(%q) = %s, want %s", s, got, want)
+		}
+		if got, want := quoteIdentifier(s), pq.QuoteIdentifier(s); got != want {
+			t.Errorf("quoteIdentifier(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
+
+func TestWithPGComment_EscapesNestedComments(t *testing.T) {
+	cases := map[string]string{
+		"a /* b":    "/* a / * b */ SELECT 1",
+		"a */ b":    "/* a * / b */ SELECT 1",
+		"/*/":       "/* / * / */ SELECT 1",
+		"*/*":       "/* * / * */ SELECT 1",
+		"job=plain": "/* job=plain */ SELECT 1",
+	}
+
+	for comment, want := range cases {
+		config := newConfig([]ConfigOption{WithPGComment(comment)})
+		if got := config.QueryInterceptors[0](context.Background(), "SELECT 1"); got != want {
+			t.Errorf("WithPGComment(%q) produced %q, want %q", comment, got, want)
+		}
+	}
+}
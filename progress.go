@@ -0,0 +1,37 @@
+package sqlxtx
+
+import "context"
+
+// ProgressReporter receives progress updates from a running transaction function
+type ProgressReporter func(processed, total int)
+
+type progressKey struct{}
+
+// WithProgressCallback registers fn to receive the progress reported by the
+// transaction function with ReportProgress, e.g. to stream a bulk import's progress
+// to a WebSocket. The reporter travels in the context of the transaction attempt,
+// which only ExecuteWithContextFunc hands to the transaction function; every other
+// Execute function rejects the option with ErrInvalidOption. fn is called
+// synchronously from ReportProgress, so it should not block.
+func WithProgressCallback(fn ProgressReporter) ConfigOption {
+	return func(c *Config) {
+		c.Progress = fn
+	}
+}
+
+// ReportProgress passes processed and total to the reporter carried by ctx. It is a
+// no-op if ctx carries no reporter, i.e. if no WithProgressCallback was registered or
+// ctx is not the one ExecuteWithContextFunc passed to the transaction function.
+func ReportProgress(ctx context.Context, processed, total int) {
+	if reporter, ok := ctx.Value(progressKey{}).(ProgressReporter); ok {
+		reporter(processed, total)
+	}
+}
+
+// withProgress returns ctx carrying config.Progress, if set
+func withProgress(ctx context.Context, config *Config) context.Context {
+	if config.Progress == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, config.Progress)
+}
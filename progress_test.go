@@ -0,0 +1,148 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWithProgressCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	var updates [][2]int
+	ctx := context.Background()
+	items := []string{"a", "b", "c"}
+	_, err = ExecuteWithContextFunc(ctx, sqlxDB, func(ctx context.Context, tx *sqlx.Tx) (any, error) {
+		for i := range items {
+			ReportProgress(ctx, i+1, len(items))
+		}
+		return nil, nil
+	}, WithProgressCallback(func(processed, total int) {
+		updates = append(updates, [2]int{processed, total})
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := [][2]int{{1, 3}, {2, 3}, {3, 3}}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("expected updates %v, got %v", want, updates)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportProgress_NoCallback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	_, err = ExecuteWithContextFunc(context.Background(), sqlxDB, func(ctx context.Context, tx *sqlx.Tx) (any, error) {
+		if ctx.Value(progressKey{}) != nil {
+			t.Error("expected no reporter in the context")
+		}
+		ReportProgress(ctx, 1, 1)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithContextFunc_ReceivesAttemptContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	type requestKey struct{}
+	ctx := context.WithValue(context.Background(), requestKey{}, "req-1")
+	result, err := ExecuteWithContextFunc(ctx, sqlxDB, func(ctx context.Context, tx *sqlx.Tx) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected the WithTimeout deadline on the attempt context")
+		}
+		return ctx.Value(requestKey{}).(string), nil
+	}, WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "req-1" {
+		t.Errorf("expected the caller's context values, got %q", result)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithProgressCallback_RejectedOutsideExecuteWithContextFunc(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	ctx := context.Background()
+	option := WithProgressCallback(func(processed, total int) {})
+
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		t.Error("expected the transaction function not to run")
+		return nil, nil
+	}, option)
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption from ExecuteContext, got %v", err)
+	}
+
+	_, err = ExecuteLazy(ctx, sqlxDB, func(tx *LazyTx) (any, error) {
+		t.Error("expected the function not to run")
+		return nil, nil
+	}, option)
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption from ExecuteLazy, got %v", err)
+	}
+
+	_, err = ExecuteManaged(ctx, NewTxManager(sqlxDB, option), func(tx *sqlx.Tx) (any, error) {
+		t.Error("expected the transaction function not to run")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption from ExecuteManaged, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,30 @@
+module github.com/huangc28/sqlx-tx/prometheus
+
+go 1.24.3
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/huangc28/sqlx-tx v0.0.0-20261014084952-4ea17f2809d0
+	github.com/jmoiron/sqlx v1.4.0
+	github.com/prometheus/client_golang v1.23.2
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/kylelemons/godebug v1.1.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.67.5 // indirect
+	github.com/prometheus/procfs v0.19.2 // indirect
+	go.yaml.in/yaml/v2 v2.4.3 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+	golang.org/x/time v0.12.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
+
+// Builds inside this repository use the parent module; replace directives are
+// ignored for dependents, which resolve the version required above.
+replace github.com/huangc28/sqlx-tx => ../
@@ -0,0 +1,91 @@
+// Package prometheus exposes Prometheus metrics for sqlxtx transactions.
+package prometheus
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+var labelNames = []string{"isolation_level", "readonly"}
+
+// metrics holds the collectors registered with a single Registerer
+type metrics struct {
+	started    *prometheus.CounterVec
+	committed  *prometheus.CounterVec
+	rolledBack *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+var (
+	registryMu sync.Mutex
+	registries = map[prometheus.Registerer]*metrics{}
+)
+
+// WithMetrics records transaction counters and durations in reg. A nil reg uses
+// prometheus.DefaultRegisterer. Collectors are registered on first use and
+// registration panics if conflicting collectors already exist.
+func WithMetrics(reg prometheus.Registerer) sqlxtx.ConfigOption {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	return sqlxtx.WithObserver(func(ctx context.Context, info sqlxtx.TxInfo) (context.Context, func(committed bool, err error)) {
+		m := metricsFor(reg)
+		labels := prometheus.Labels{
+			"isolation_level": info.IsolationLevel().String(),
+			"readonly":        strconv.FormatBool(info.ReadOnly()),
+		}
+
+		start := time.Now()
+		m.started.With(labels).Inc()
+
+		return ctx, func(committed bool, err error) {
+			m.duration.With(labels).Observe(time.Since(start).Seconds())
+			if committed {
+				m.committed.With(labels).Inc()
+			} else {
+				m.rolledBack.With(labels).Inc()
+			}
+		}
+	})
+}
+
+// metricsFor returns the collectors for reg, registering them on first use
+func metricsFor(reg prometheus.Registerer) *metrics {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if m, ok := registries[reg]; ok {
+		return m
+	}
+
+	m := &metrics{
+		started: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sqlx_tx_started_total",
+			Help: "Number of transactions started.",
+		}, labelNames),
+		committed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sqlx_tx_committed_total",
+			Help: "Number of transactions committed.",
+		}, labelNames),
+		rolledBack: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sqlx_tx_rolledback_total",
+			Help: "Number of transactions rolled back.",
+		}, labelNames),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sqlx_tx_duration_seconds",
+			Help:    "Duration of transactions from begin to commit or rollback.",
+			Buckets: prometheus.DefBuckets,
+		}, labelNames),
+	}
+	reg.MustRegister(m.started, m.committed, m.rolledBack, m.duration)
+
+	registries[reg] = m
+	return m
+}
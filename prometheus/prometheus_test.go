@@ -0,0 +1,89 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestWithMetrics_CountsOutcomes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	reg := prometheus.NewRegistry()
+	ctx := context.Background()
+
+	_, err = sqlxtx.ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithMetrics(reg))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = sqlxtx.ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, errors.New("test error")
+	}, WithMetrics(reg))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	m := metricsFor(reg)
+	if got := testutil.ToFloat64(m.started); got != 2 {
+		t.Errorf("expected 2 started transactions, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.committed); got != 1 {
+		t.Errorf("expected 1 committed transaction, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.rolledBack); got != 1 {
+		t.Errorf("expected 1 rolled back transaction, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.duration); got != 1 {
+		t.Errorf("expected 1 duration series, got %d", got)
+	}
+}
+
+func TestWithMetrics_CommitHookFailureCountsAsCommitted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	reg := prometheus.NewRegistry()
+	_, err = sqlxtx.ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithMetrics(reg), sqlxtx.WithOnCommit(func() { panic("hook failed") }))
+	if err == nil {
+		t.Fatal("expected the commit hook failure to be returned")
+	}
+
+	m := metricsFor(reg)
+	if got := testutil.ToFloat64(m.committed); got != 1 {
+		t.Errorf("expected 1 committed transaction, got %v", got)
+	}
+	if got := testutil.CollectAndCount(m.rolledBack); got != 0 {
+		t.Errorf("expected no rolled back series, got %d", got)
+	}
+}
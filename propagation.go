@@ -0,0 +1,65 @@
+package sqlxtx
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Propagation controls how ExecuteContext treats a transaction already stored in the context
+type Propagation int
+
+const (
+	// PropagationRequired joins the transaction in the context if present, otherwise begins a new one
+	PropagationRequired Propagation = iota + 1
+	// PropagationRequiresNew always begins a new transaction
+	PropagationRequiresNew
+	// PropagationNested runs inside a savepoint of the transaction in the context if present,
+	// otherwise begins a new one
+	PropagationNested
+)
+
+// txContextKey is the context key under which InjectTx stores a transaction
+type txContextKey struct{}
+
+// InjectTx returns a copy of ctx carrying tx
+func InjectTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored in ctx by InjectTx, if any
+func TxFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sqlx.Tx)
+	return tx, ok && tx != nil
+}
+
+// WithPropagation makes ExecuteContext consult the context for an existing transaction.
+// Without this option a new transaction is always started.
+func WithPropagation(p Propagation) ConfigOption {
+	return func(c *Config) {
+		c.Propagation = p
+	}
+}
+
+// executePropagated runs txFunc in the transaction found in ctx when the configured
+// propagation allows it. The boolean result reports whether the call was handled.
+func executePropagated[T any](ctx context.Context, config *Config, txFunc TxFunc[T], options []ConfigOption) (T, bool, error) {
+	var zero T
+
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return zero, false, nil
+	}
+
+	switch config.Propagation {
+	case PropagationRequired:
+		// The outer transaction owns commit and rollback
+		result, err := txFunc(tx)
+		return result, true, err
+	case PropagationNested:
+		result, err := ExecuteNested(ctx, tx, txFunc, options...)
+		return result, true, err
+	default:
+		return zero, false, nil
+	}
+}
@@ -0,0 +1,27 @@
+package sqlxtx
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter throttles how often transactions are started. Wait blocks until a
+// transaction may begin or returns an error, typically because ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithRateLimiter calls limiter.Wait before every BEGIN. If Wait fails its error is
+// returned, no transaction is started and observers are not notified of the attempt.
+func WithRateLimiter(limiter RateLimiter) ConfigOption {
+	return func(c *Config) {
+		c.RateLimiter = limiter
+	}
+}
+
+// NewTokenBucketLimiter returns a RateLimiter allowing rate transactions per second
+// on average, with bursts of up to burst transactions
+func NewTokenBucketLimiter(r float64, burst int) RateLimiter {
+	return rate.NewLimiter(rate.Limit(r), burst)
+}
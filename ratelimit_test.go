@@ -0,0 +1,104 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_RateLimiterErrorSkipsBegin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	limiter := NewTokenBucketLimiter(0.001, 1)
+	txFunc := func(tx *sqlx.Tx) (any, error) { return nil, nil }
+
+	if _, err := ExecuteContext(context.Background(), sqlxDB, txFunc, WithRateLimiter(limiter)); err != nil {
+		t.Errorf("expected the first transaction to use the burst, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = ExecuteContext(ctx, sqlxDB, txFunc, WithRateLimiter(limiter))
+	if err == nil {
+		t.Error("expected the rate limiter to fail the second transaction")
+	}
+	if IsBeginError(err) {
+		t.Errorf("expected no transaction to be started, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type stubLimiter struct{ err error }
+
+func (l stubLimiter) Wait(ctx context.Context) error { return l.err }
+
+func TestExecuteContext_RateLimiterErrorReturnedAsIs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	waitErr := errors.New("limiter closed")
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithRateLimiter(stubLimiter{waitErr}))
+
+	if !errors.Is(err, waitErr) {
+		t.Errorf("expected limiter error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_RateLimiterErrorSkipsObservers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	observed := 0
+	observer := func(ctx context.Context, info TxInfo) (context.Context, func(committed bool, err error)) {
+		observed++
+		return ctx, nil
+	}
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithRateLimiter(stubLimiter{errors.New("limiter closed")}), WithObserver(observer))
+
+	if err == nil {
+		t.Fatal("expected the limiter error")
+	}
+	if observed != 0 {
+		t.Errorf("expected no observer call for a rate-limited attempt, got %d", observed)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build RELEASE_BUILD
+
+package sqlxtx
+
+// releaseBuild reports whether the package was built with the RELEASE_BUILD tag,
+// which makes Config.Validate warn about debug-only options
+const releaseBuild = true
@@ -0,0 +1,32 @@
+//go:build RELEASE_BUILD
+
+package sqlxtx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestValidate_WarnsAboutPGLogParametersInReleaseBuild(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	logParametersWarning = sync.Once{}
+
+	config := &Config{}
+	WithPGLogParameters(-1)(config)
+
+	for i := 0; i < 3; i++ {
+		if err := config.Validate(); err != nil {
+			t.Fatalf("expected a warning, not an error, got %v", err)
+		}
+	}
+	if n := strings.Count(buf.String(), "WithPGLogParameters"); n != 1 {
+		t.Errorf("expected exactly one warning, got %d in %q", n, buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Executer is a database handle that can begin transactions. *sqlx.DB and
+// *PrimaryReplicaDB both satisfy it.
+type Executer interface {
+	DriverName() string
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+// PrimaryReplicaDB routes read-only transactions to replicas and everything else
+// to the primary
+type PrimaryReplicaDB struct {
+	primary  *sqlx.DB
+	replicas []*sqlx.DB
+	next     atomic.Uint64
+}
+
+// NewPrimaryReplicaDB creates a PrimaryReplicaDB. Replicas are picked round-robin;
+// without any replicas all transactions use the primary.
+func NewPrimaryReplicaDB(primary *sqlx.DB, replicas ...*sqlx.DB) *PrimaryReplicaDB {
+	return &PrimaryReplicaDB{
+		primary:  primary,
+		replicas: append([]*sqlx.DB(nil), replicas...),
+	}
+}
+
+// Primary returns the primary database handle
+func (db *PrimaryReplicaDB) Primary() *sqlx.DB {
+	return db.primary
+}
+
+// DriverName returns the driver name of the primary
+func (db *PrimaryReplicaDB) DriverName() string {
+	return db.primary.DriverName()
+}
+
+// BeginTxx begins a transaction on a replica when opts is read-only and on the
+// primary otherwise
+func (db *PrimaryReplicaDB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return db.route(opts).BeginTxx(ctx, opts)
+}
+
+// ExecContext runs query on the primary outside a transaction
+func (db *PrimaryReplicaDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.primary.ExecContext(ctx, query, args...)
+}
+
+// route picks the handle a transaction with opts is begun on
+func (db *PrimaryReplicaDB) route(opts *sql.TxOptions) *sqlx.DB {
+	if opts != nil && opts.ReadOnly && len(db.replicas) > 0 {
+		return db.replica()
+	}
+	return db.primary
+}
+
+// replica returns the next replica in round-robin order
+func (db *PrimaryReplicaDB) replica() *sqlx.DB {
+	n := db.next.Add(1) - 1
+	return db.replicas[n%uint64(len(db.replicas))]
+}
@@ -0,0 +1,85 @@
+package sqlxtx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPrimaryReplicaDB_RoutesReadOnlyToReplicas(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer primary.Close()
+
+	replica1, replica1Mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer replica1.Close()
+
+	replica2, replica2Mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer replica2.Close()
+
+	db := NewPrimaryReplicaDB(
+		sqlx.NewDb(primary, "postgres"),
+		sqlx.NewDb(replica1, "postgres"),
+		sqlx.NewDb(replica2, "postgres"),
+	)
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectCommit()
+	replica1Mock.ExpectBegin()
+	replica1Mock.ExpectCommit()
+	replica1Mock.ExpectBegin()
+	replica1Mock.ExpectCommit()
+	replica2Mock.ExpectBegin()
+	replica2Mock.ExpectCommit()
+
+	ctx := context.Background()
+	noop := func(tx *sqlx.Tx) error { return nil }
+
+	if err := ExecuteVoidContext(ctx, db, noop); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := ExecuteVoidContext(ctx, db, noop, WithReadOnly()); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+
+	for name, mock := range map[string]sqlmock.Sqlmock{"primary": primaryMock, "replica1": replica1Mock, "replica2": replica2Mock} {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations on %s: %s", name, err)
+		}
+	}
+}
+
+func TestPrimaryReplicaDB_WithoutReplicasUsesPrimary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err = ExecuteVoidContext(context.Background(), NewPrimaryReplicaDB(sqlx.NewDb(db, "postgres")), func(tx *sqlx.Tx) error {
+		return nil
+	}, WithReadOnly())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,133 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// BackoffFunc returns the delay to wait before the given retry attempt.
+// The attempt number starts at 1 for the first retry.
+type BackoffFunc func(attempt int) time.Duration
+
+// WithRetry retries the whole transaction up to maxAttempts times when it fails
+// with a retryable error (see IsRetryableError). backoff may be nil to retry immediately.
+func WithRetry(maxAttempts int, backoff BackoffFunc) ConfigOption {
+	return func(c *Config) {
+		c.MaxAttempts = maxAttempts
+		c.Backoff = backoff
+	}
+}
+
+// WithMaxRetries allows up to n retries after the first attempt. Without it no
+// retries occur, even when a backoff is configured. Every attempt runs with the
+// caller's ctx, so its values (trace spans, request IDs) are seen by all attempts.
+func WithMaxRetries(n int) ConfigOption {
+	return func(c *Config) {
+		c.MaxAttempts = n + 1
+	}
+}
+
+// WithExponentialBackoff waits baseDelay before the first retry and multiplies the
+// delay by factor for each further retry, capped at maxDelay (0 means no cap)
+func WithExponentialBackoff(baseDelay time.Duration, factor float64, maxDelay time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.Backoff = exponentialBackoff(baseDelay, factor, maxDelay)
+	}
+}
+
+// WithJitter randomizes each backoff delay by up to ±fraction of its value,
+// e.g. 0.2 turns a 100ms delay into one between 80ms and 120ms
+func WithJitter(fraction float64) ConfigOption {
+	return func(c *Config) {
+		c.Jitter = fraction
+	}
+}
+
+// RetryPredicate reports whether err, returned by the given attempt, should be retried
+type RetryPredicate func(err error, attempt int) bool
+
+// WithRetryPredicate adds custom retry logic, e.g. for application-level optimistic-lock
+// conflicts. An attempt is retried if either the predicate or IsRetryableError
+// reports true. The attempt ceiling is still set by WithRetry.
+func WithRetryPredicate(fn RetryPredicate) ConfigOption {
+	return func(c *Config) {
+		c.RetryPredicate = fn
+	}
+}
+
+// WithFreshContextPerRetry calls fn with the caller's ctx before every attempt,
+// including the first, and runs the attempt with the context it returns, e.g. to start
+// a new trace span per attempt. fn must derive the result from ctx, not return nil.
+func WithFreshContextPerRetry(fn func(ctx context.Context) context.Context) ConfigOption {
+	return func(c *Config) {
+		c.AttemptContext = fn
+	}
+}
+
+// IsRetryableError reports whether err is a deadlock or serialization failure
+// (see IsDeadlock and IsSerializationFailure)
+func IsRetryableError(err error) bool {
+	return IsDeadlock(err) || IsSerializationFailure(err)
+}
+
+// shouldRetry decides whether another attempt should be made after err
+func shouldRetry(ctx context.Context, config *Config, attempt int, err error) bool {
+	if err == nil || attempt >= config.MaxAttempts {
+		return false
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	if IsRetryableError(err) {
+		return true
+	}
+	if config.OptimisticLocking && errors.Is(err, ErrVersionConflict) {
+		return true
+	}
+	return config.RetryPredicate != nil && config.RetryPredicate(err, attempt)
+}
+
+// waitBackoff sleeps for the configured backoff delay, returning early if ctx is done
+func waitBackoff(ctx context.Context, config *Config, attempt int) error {
+	if config.Backoff == nil {
+		return nil
+	}
+
+	delay := applyJitter(config.Backoff(attempt), config.Jitter)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// exponentialBackoff returns a BackoffFunc growing by factor from baseDelay up to maxDelay
+func exponentialBackoff(baseDelay time.Duration, factor float64, maxDelay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := float64(baseDelay) * math.Pow(factor, float64(attempt-1))
+		if maxDelay > 0 && delay > float64(maxDelay) {
+			return maxDelay
+		}
+		return time.Duration(delay)
+	}
+}
+
+// applyJitter spreads delay uniformly within ±fraction of its value
+func applyJitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || delay <= 0 {
+		return delay
+	}
+	offset := (rand.Float64()*2 - 1) * fraction * float64(delay)
+	return delay + time.Duration(offset)
+}
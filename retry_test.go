@@ -0,0 +1,318 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type sqlStateError struct {
+	code string
+}
+
+func (e *sqlStateError) Error() string    { return "sqlstate " + e.code }
+func (e *sqlStateError) SQLState() string { return e.code }
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&sqlStateError{"40001"}, true},
+		{&sqlStateError{"40P01"}, true},
+		{fmt.Errorf("wrapped: %w", &sqlStateError{"40001"}), true},
+		{&sqlStateError{"23505"}, false},
+		{errors.New("plain"), false},
+		{nil, false},
+	}
+
+	for _, c := range cases {
+		if got := IsRetryableError(c.err); got != c.want {
+			t.Errorf("IsRetryableError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestExecuteContext_RetryOnSerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	var backoffAttempts []int
+	backoff := func(attempt int) time.Duration {
+		backoffAttempts = append(backoffAttempts, attempt)
+		return time.Millisecond
+	}
+
+	calls := 0
+	result, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, &sqlStateError{"40001"}
+		}
+		return 42, nil
+	}, WithRetry(3, backoff))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if result != 42 {
+		t.Errorf("expected result to be 42, got %v", result)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+	if len(backoffAttempts) != 1 || backoffAttempts[0] != 1 {
+		t.Errorf("expected backoff to be called once with attempt 1, got %v", backoffAttempts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_RetryStopsAtMaxAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	calls := 0
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		return nil, &sqlStateError{"40P01"}
+	}, WithRetry(2, nil))
+
+	if !IsRetryableError(err) {
+		t.Errorf("expected retryable error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_NoRetryWhenContextCancelled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		cancel()
+		return nil, &sqlStateError{"40001"}
+	}, WithRetry(5, nil))
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestExecuteContext_RetryPredicate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	errConflict := errors.New("version conflict")
+	var seenAttempts []int
+	predicate := func(err error, attempt int) bool {
+		seenAttempts = append(seenAttempts, attempt)
+		return errors.Is(err, errConflict)
+	}
+
+	calls := 0
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		return nil, errConflict
+	}, WithRetry(3, nil), WithRetryPredicate(predicate))
+
+	if !errors.Is(err, errConflict) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	if len(seenAttempts) != 2 || seenAttempts[0] != 1 || seenAttempts[1] != 2 {
+		t.Errorf("expected predicate to see attempts [1 2], got %v", seenAttempts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := exponentialBackoff(50*time.Millisecond, 2.0, 300*time.Millisecond)
+
+	want := []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := backoff(i + 1); got != w {
+			t.Errorf("attempt %d: expected %v, got %v", i+1, w, got)
+		}
+	}
+}
+
+func TestApplyJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		got := applyJitter(100*time.Millisecond, 0.2)
+		if got < 80*time.Millisecond || got > 120*time.Millisecond {
+			t.Fatalf("expected jittered delay within 80ms-120ms, got %v", got)
+		}
+	}
+
+	if got := applyJitter(100*time.Millisecond, 0); got != 100*time.Millisecond {
+		t.Errorf("expected no jitter, got %v", got)
+	}
+}
+
+func TestWithMaxRetries(t *testing.T) {
+	config := &Config{}
+	WithExponentialBackoff(time.Millisecond, 2, 0)(config)
+	if shouldRetry(context.Background(), config, 1, &sqlStateError{"40001"}) {
+		t.Error("expected no retry without WithMaxRetries")
+	}
+
+	WithMaxRetries(2)(config)
+	if !shouldRetry(context.Background(), config, 2, &sqlStateError{"40001"}) {
+		t.Error("expected a retry after the second attempt")
+	}
+	if shouldRetry(context.Background(), config, 3, &sqlStateError{"40001"}) {
+		t.Error("expected no retry after two retries")
+	}
+}
+
+func TestExecuteContext_RetryGetsFreshTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	// Both attempts together outlast the timeout; each alone does not
+	calls := 0
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		time.Sleep(60 * time.Millisecond)
+		if calls == 1 {
+			return nil, &sqlStateError{"40001"}
+		}
+		return nil, nil
+	}, WithTimeout(100*time.Millisecond), WithMaxRetries(1))
+
+	if err != nil {
+		t.Errorf("expected the second attempt to commit, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+type attemptKey struct{}
+
+func TestWithFreshContextPerRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	type userKey struct{}
+	ctx := context.WithValue(context.Background(), userKey{}, "u-1")
+
+	var seen []context.Context
+	attempts := 0
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		if len(seen) == 1 {
+			return nil, &sqlStateError{"40001"}
+		}
+		return nil, nil
+	}, WithMaxRetries(1), WithFreshContextPerRetry(func(ctx context.Context) context.Context {
+		attempts++
+		seen = append(seen, ctx)
+		return context.WithValue(ctx, attemptKey{}, attempts)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected fn to be called for 2 attempts, got %d", len(seen))
+	}
+	for i, c := range seen {
+		if c.Value(userKey{}) != "u-1" {
+			t.Errorf("attempt %d: expected the caller's context values to be preserved", i+1)
+		}
+		if c.Value(attemptKey{}) != nil {
+			t.Errorf("attempt %d: expected fn to receive the caller's context, not a previous attempt's", i+1)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
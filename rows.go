@@ -0,0 +1,134 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// CollectRows scans every remaining row into a []T and closes rows. Structs are
+// scanned by column name with StructScan; other types, sql.Scanner implementations,
+// and structs without exported fields (such as time.Time) are scanned directly.
+func CollectRows[T any](rows *sqlx.Rows) ([]T, error) {
+	defer rows.Close()
+
+	var results []T
+	for rows.Next() {
+		item, err := scanRow[T](rows)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, item)
+	}
+	if err := rows.Err(); err != nil {
+		return results, fmt.Errorf("failed to iterate rows: %w", err)
+	}
+	return results, nil
+}
+
+// CollectRowsContext runs query inside tx and collects the results into a []T
+func CollectRowsContext[T any](ctx context.Context, tx *sqlx.Tx, query string, args ...any) ([]T, error) {
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %q: %w", query, err)
+	}
+	return CollectRows[T](rows)
+}
+
+// ForEachRow runs query inside tx and calls fn for each row as it is scanned, without
+// holding the whole result in memory. Iteration stops at the first error from fn,
+// and returns ctx.Err() if the context is cancelled mid-iteration.
+func ForEachRow[T any](ctx context.Context, tx *sqlx.Tx, fn func(T) error, query string, args ...any) error {
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		item, err := scanRow[T](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate rows: %w", err)
+	}
+	return nil
+}
+
+// MapRows runs query inside tx, scans each row into an A and collects transform's
+// result for it, for when the row type differs from the application type. On the
+// first error the results gathered so far are returned along with it.
+func MapRows[A, B any](ctx context.Context, tx *sqlx.Tx, transform func(A) (B, error), query string, args ...any) ([]B, error) {
+	var results []B
+	err := ForEachRow(ctx, tx, func(row A) error {
+		item, err := transform(row)
+		if err != nil {
+			return err
+		}
+		results = append(results, item)
+		return nil
+	}, query, args...)
+	return results, err
+}
+
+// ReduceRows runs query inside tx and folds the rows into an accumulator, one row at
+// a time, starting from initial. On an error from reduce, scanning or the context,
+// the accumulator as of the last successful row is returned along with it.
+func ReduceRows[Row, Acc any](ctx context.Context, tx *sqlx.Tx, initial Acc, reduce func(Acc, Row) (Acc, error), query string, args ...any) (Acc, error) {
+	acc := initial
+	err := ForEachRow(ctx, tx, func(row Row) error {
+		next, err := reduce(acc, row)
+		if err != nil {
+			return err
+		}
+		acc = next
+		return nil
+	}, query, args...)
+	return acc, err
+}
+
+// scanRow scans the current row into a new T
+func scanRow[T any](rows *sqlx.Rows) (T, error) {
+	var item T
+	var err error
+	if isScannable(reflect.TypeOf(item)) {
+		err = rows.Scan(&item)
+	} else {
+		err = rows.StructScan(&item)
+	}
+	if err != nil {
+		return item, fmt.Errorf("failed to scan row: %w", err)
+	}
+	return item, nil
+}
+
+// isScannable mirrors sqlx: a type is scanned directly unless it is a struct with
+// exported fields that does not implement sql.Scanner
+func isScannable(t reflect.Type) bool {
+	if t == nil || reflect.PointerTo(t).Implements(scannerType) {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
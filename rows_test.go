@@ -0,0 +1,258 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+type rowUser struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestCollectRowsContext_Structs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b"))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	users, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) ([]rowUser, error) {
+		return CollectRowsContext[rowUser](ctx, tx, "SELECT id, name FROM users")
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if want := []rowUser{{1, "a"}, {2, "b"}}; !reflect.DeepEqual(users, want) {
+		t.Errorf("expected %v, got %v", want, users)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCollectRows_ScalarsAndRowError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	rowErr := errors.New("connection lost")
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, rowErr))
+
+	tx, err := sqlxDB.Beginx()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	rows, err := tx.Queryx("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("failed to query: %v", err)
+	}
+
+	ids, err := CollectRows[int](rows)
+	if !errors.Is(err, rowErr) {
+		t.Errorf("expected row error, got %v", err)
+	}
+	if want := []int{1}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected partial results %v, got %v", want, ids)
+	}
+}
+
+func TestForEachRow_StopsOnCallbackError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b").AddRow(3, "c"))
+	mock.ExpectRollback()
+
+	stopErr := errors.New("stop")
+	var seen []int
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		return ForEachRow(ctx, tx, func(u rowUser) error {
+			seen = append(seen, u.ID)
+			if u.ID == 2 {
+				return stopErr
+			}
+			return nil
+		}, "SELECT id, name FROM users")
+	})
+
+	if !errors.Is(err, stopErr) {
+		t.Errorf("expected stop error, got %v", err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("expected %v, got %v", want, seen)
+	}
+}
+
+func TestForEachRow_ContextCancelled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	tx, err := sqlxDB.Beginx()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	err = ForEachRow(ctx, tx, func(id int) error {
+		calls++
+		cancel()
+		return nil
+	}, "SELECT id FROM users")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestMapRows_TransformsAndStopsOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, name FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "").AddRow(3, "c"))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	errEmptyName := errors.New("empty name")
+	names, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) ([]string, error) {
+		return MapRows(ctx, tx, func(u rowUser) (string, error) {
+			if u.Name == "" {
+				return "", errEmptyName
+			}
+			return u.Name + "!", nil
+		}, "SELECT id, name FROM users")
+	})
+
+	if !errors.Is(err, errEmptyName) {
+		t.Errorf("expected empty name error, got %v", err)
+	}
+	if want := []string{"a!"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected partial results %v, got %v", want, names)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReduceRows_Sum(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT amount FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10).AddRow(20).AddRow(12))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	total, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return ReduceRows(ctx, tx, 0, func(sum int, amount int) (int, error) {
+			return sum + amount, nil
+		}, "SELECT amount FROM orders")
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if total != 42 {
+		t.Errorf("expected total 42, got %d", total)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReduceRows_ReturnsPartialAccumulatorOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT amount FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"amount"}).AddRow(10).AddRow(-1).AddRow(12))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	errNegative := errors.New("negative amount")
+	total, err := ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (int, error) {
+		return ReduceRows(ctx, tx, 0, func(sum int, amount int) (int, error) {
+			if amount < 0 {
+				return sum, errNegative
+			}
+			return sum + amount, nil
+		}, "SELECT amount FROM orders")
+	})
+
+	if !errors.Is(err, errNegative) {
+		t.Errorf("expected negative amount error, got %v", err)
+	}
+	if total != 10 {
+		t.Errorf("expected partial total 10, got %d", total)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
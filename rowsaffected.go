@@ -0,0 +1,70 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrRowsAffectedConstraint is returned when a WithMinRowsAffected or
+// WithMaxRowsAffected assertion fails
+var ErrRowsAffectedConstraint = errors.New("rows affected constraint violated")
+
+// TxResultFunc is a TxFunc that also returns the sql.Result of its main statement
+type TxResultFunc[T any] func(tx *sqlx.Tx) (T, sql.Result, error)
+
+// WithMinRowsAffected makes ExecuteWithResult roll back unless the returned
+// sql.Result affected at least n rows. Other entry points reject it with ErrInvalidOption.
+func WithMinRowsAffected(n int64) ConfigOption {
+	return func(c *Config) {
+		c.MinRowsAffected = &n
+	}
+}
+
+// WithMaxRowsAffected makes ExecuteWithResult roll back if the returned sql.Result
+// affected more than n rows. Other entry points reject it with ErrInvalidOption.
+func WithMaxRowsAffected(n int64) ConfigOption {
+	return func(c *Config) {
+		c.MaxRowsAffected = &n
+	}
+}
+
+// ExecuteWithResult runs fn within a transaction and checks the rows affected by the
+// sql.Result it returns against WithMinRowsAffected and WithMaxRowsAffected before
+// committing. A violated constraint rolls back and returns ErrRowsAffectedConstraint.
+func ExecuteWithResult[T any](ctx context.Context, db Executer, fn TxResultFunc[T], options ...ConfigOption) (T, error) {
+	return execute(ctx, db, entryWithResult, func(config *Config) (ContextTxFunc[T], error) {
+		return func(_ context.Context, tx *sqlx.Tx) (T, error) {
+			result, res, err := fn(tx)
+			if err != nil {
+				return result, err
+			}
+			return result, checkRowsAffected(res, config)
+		}, nil
+	}, options)
+}
+
+// checkRowsAffected enforces the rows affected constraints held in config
+func checkRowsAffected(res sql.Result, config *Config) error {
+	if config.MinRowsAffected == nil && config.MaxRowsAffected == nil {
+		return nil
+	}
+	if res == nil {
+		return fmt.Errorf("%w: no sql.Result returned", ErrRowsAffectedConstraint)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if min := config.MinRowsAffected; min != nil && n < *min {
+		return fmt.Errorf("%w: %d rows affected, expected at least %d", ErrRowsAffectedConstraint, n, *min)
+	}
+	if max := config.MaxRowsAffected; max != nil && n > *max {
+		return fmt.Errorf("%w: %d rows affected, expected at most %d", ErrRowsAffectedConstraint, n, *max)
+	}
+	return nil
+}
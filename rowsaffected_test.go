@@ -0,0 +1,149 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteWithResult_WithinRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	_, err = ExecuteWithResult(ctx, sqlxDB, func(tx *sqlx.Tx) (any, sql.Result, error) {
+		res, err := tx.ExecContext(ctx, "UPDATE users SET active = true WHERE id = $1", 1)
+		return nil, res, err
+	}, WithMinRowsAffected(1), WithMaxRowsAffected(1))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithResult_TooFewRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	_, err = ExecuteWithResult(ctx, sqlxDB, func(tx *sqlx.Tx) (any, sql.Result, error) {
+		res, err := tx.ExecContext(ctx, "UPDATE users SET active = true WHERE id = $1", 1)
+		return nil, res, err
+	}, WithMinRowsAffected(1))
+
+	if !errors.Is(err, ErrRowsAffectedConstraint) {
+		t.Errorf("expected ErrRowsAffectedConstraint, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithResult_TooManyRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 50))
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	_, err = ExecuteWithResult(ctx, sqlxDB, func(tx *sqlx.Tx) (any, sql.Result, error) {
+		res, err := tx.ExecContext(ctx, "DELETE FROM users WHERE inactive")
+		return nil, res, err
+	}, WithMaxRowsAffected(10))
+
+	if !errors.Is(err, ErrRowsAffectedConstraint) {
+		t.Errorf("expected ErrRowsAffectedConstraint, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_RejectsRowsAffectedLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	for _, option := range []ConfigOption{WithMinRowsAffected(1), WithMaxRowsAffected(1)} {
+		_, err := ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+			t.Error("expected the transaction function not to run")
+			return nil, nil
+		}, option)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("expected ErrInvalidOption, got %v", err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteWithResult_AppliesOptionsOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	applied := 0
+	countingOption := func(c *Config) { applied++ }
+
+	_, err = ExecuteWithResult(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, sql.Result, error) {
+		return nil, sqlmock.NewResult(0, 1), nil
+	}, WithMinRowsAffected(1), countingOption)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected the options to be applied once, got %d", applied)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,79 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSavepointReleased is returned when a Savepoint is used after Release
+var ErrSavepointReleased = errors.New("savepoint already released")
+
+// Savepoint is a named savepoint of a transaction, for finer control than
+// ExecuteNested gives. It is not safe for concurrent use.
+type Savepoint struct {
+	tx       *sqlx.Tx
+	name     string
+	released bool
+}
+
+// NewSavepoint creates a savepoint in tx. An empty name generates one of the form
+// sp_<random hex>; other names must be plain identifiers.
+func NewSavepoint(ctx context.Context, tx *sqlx.Tx, name string) (*Savepoint, error) {
+	if name == "" {
+		var err error
+		if name, err = generateSavepointName(); err != nil {
+			return nil, err
+		}
+	}
+	if !savepointNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid savepoint name %q", name)
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+	return &Savepoint{tx: tx, name: name}, nil
+}
+
+// Name returns the savepoint name
+func (sp *Savepoint) Name() string {
+	return sp.name
+}
+
+// Release releases the savepoint, keeping the work done since it was created
+func (sp *Savepoint) Release(ctx context.Context) error {
+	if sp.released {
+		return fmt.Errorf("%w: %s", ErrSavepointReleased, sp.name)
+	}
+	if _, err := sp.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+sp.name); err != nil {
+		return fmt.Errorf("failed to release savepoint %s: %w", sp.name, err)
+	}
+	sp.released = true
+	return nil
+}
+
+// RollbackTo undoes the work done since the savepoint was created. The outer
+// transaction stays usable and the savepoint stays in place, so it can be rolled
+// back to again or released.
+func (sp *Savepoint) RollbackTo(ctx context.Context) error {
+	if sp.released {
+		return fmt.Errorf("%w: %s", ErrSavepointReleased, sp.name)
+	}
+	if _, err := sp.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp.name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint %s: %w", sp.name, err)
+	}
+	return nil
+}
+
+// WithAutoRelease runs fn on the savepoint's transaction and releases sp if fn
+// succeeds. On failure the savepoint is left in place for the caller to roll back to.
+func WithAutoRelease[T any](ctx context.Context, sp *Savepoint, fn TxFunc[T]) (T, error) {
+	result, err := fn(sp.tx)
+	if err != nil {
+		return result, err
+	}
+	return result, sp.Release(ctx)
+}
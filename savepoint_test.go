@@ -0,0 +1,105 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestSavepoint_RollbackToThenRelease(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT before_coupon").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE orders SET discount = 10").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT before_coupon").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT before_coupon").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		sp, err := NewSavepoint(ctx, tx, "before_coupon")
+		if err != nil {
+			return err
+		}
+		if sp.Name() != "before_coupon" {
+			t.Errorf("expected name before_coupon, got %s", sp.Name())
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE orders SET discount = 10"); err != nil {
+			return err
+		}
+		if err := sp.RollbackTo(ctx); err != nil {
+			return err
+		}
+		if err := sp.Release(ctx); err != nil {
+			return err
+		}
+		if err := sp.Release(ctx); !errors.Is(err, ErrSavepointReleased) {
+			t.Errorf("expected ErrSavepointReleased, got %v", err)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWithAutoRelease(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT step_one").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT step_one").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT step_two").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT step_two").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := context.Background()
+	stepErr := errors.New("step failed")
+	err = ExecuteVoidContext(ctx, sqlxDB, func(tx *sqlx.Tx) error {
+		one, err := NewSavepoint(ctx, tx, "step_one")
+		if err != nil {
+			return err
+		}
+		if _, err := WithAutoRelease(ctx, one, func(tx *sqlx.Tx) (any, error) { return nil, nil }); err != nil {
+			return err
+		}
+
+		two, err := NewSavepoint(ctx, tx, "step_two")
+		if err != nil {
+			return err
+		}
+		if _, err := WithAutoRelease(ctx, two, func(tx *sqlx.Tx) (any, error) { return nil, stepErr }); !errors.Is(err, stepErr) {
+			t.Errorf("expected step error, got %v", err)
+		}
+		return two.RollbackTo(ctx)
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SoftDelete marks the row of table whose pkColumn equals pkValue as deleted by
+// setting its deleted_at column to CURRENT_TIMESTAMP. Rows that are already deleted
+// are left alone; if nothing was updated, ErrNotFound is returned.
+func SoftDelete(ctx context.Context, tx *sqlx.Tx, table, pkColumn string, pkValue any) (sql.Result, error) {
+	query, err := softDeleteQuery(table, pkColumn, "= ?")
+	if err != nil {
+		return nil, err
+	}
+	return execSoftDelete(ctx, tx, table, tx.Rebind(query), pkValue)
+}
+
+// SoftDeleteBatch is like SoftDelete for every row whose pkColumn is in pkValues.
+// ErrNotFound is returned only if none of the rows was updated.
+func SoftDeleteBatch[K any](ctx context.Context, tx *sqlx.Tx, table, pkColumn string, pkValues []K) (sql.Result, error) {
+	if len(pkValues) == 0 {
+		return bulkResult{}, nil
+	}
+	query, err := softDeleteQuery(table, pkColumn, "IN (?)")
+	if err != nil {
+		return nil, err
+	}
+	query, args, err := sqlx.In(query, pkValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand keys for %s: %w", table, err)
+	}
+	return execSoftDelete(ctx, tx, table, tx.Rebind(query), args...)
+}
+
+// softDeleteQuery builds the UPDATE statement with the given key condition
+func softDeleteQuery(table, pkColumn, condition string) (string, error) {
+	if err := validateIdentifier("table", table); err != nil {
+		return "", err
+	}
+	if err := validateIdentifier("column", pkColumn); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("UPDATE %s SET deleted_at = CURRENT_TIMESTAMP WHERE %s %s AND deleted_at IS NULL",
+		table, pkColumn, condition), nil
+}
+
+// execSoftDelete runs query and turns zero affected rows into ErrNotFound
+func execSoftDelete(ctx context.Context, tx *sqlx.Tx, table, query string, args ...any) (sql.Result, error) {
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to soft delete from %s: %w", table, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return res, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if n == 0 {
+		return res, fmt.Errorf("%w: no live rows in %s", ErrNotFound, table)
+	}
+	return res, nil
+}
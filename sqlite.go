@@ -0,0 +1,74 @@
+package sqlxtx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLite locking modes for BEGIN
+const (
+	sqliteBeginImmediate = "IMMEDIATE"
+	sqliteBeginExclusive = "EXCLUSIVE"
+)
+
+// WithSQLiteImmediate starts the transaction with BEGIN IMMEDIATE so the write lock
+// is taken up front instead of failing with SQLITE_BUSY at write time (SQLite only)
+func WithSQLiteImmediate() ConfigOption {
+	return func(c *Config) {
+		c.SQLiteBeginMode = sqliteBeginImmediate
+	}
+}
+
+// WithSQLiteExclusive starts the transaction with BEGIN EXCLUSIVE (SQLite only)
+func WithSQLiteExclusive() ConfigOption {
+	return func(c *Config) {
+		c.SQLiteBeginMode = sqliteBeginExclusive
+	}
+}
+
+// WithSQLiteBusyTimeout runs PRAGMA busy_timeout after BEGIN so SQLite waits up to d
+// for a competing writer instead of failing with SQLITE_BUSY at once (SQLite only).
+// The pragma stays on the pooled connection afterwards. Unless configured otherwise,
+// it also retries the transaction up to 3 times when it still fails with SQLITE_BUSY.
+func WithSQLiteBusyTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SQLiteBusyTimeout = d
+		if c.MaxAttempts == 0 {
+			c.MaxAttempts = 4
+		}
+		if c.RetryPredicate == nil {
+			c.RetryPredicate = func(err error, attempt int) bool {
+				return IsSQLiteBusy(err)
+			}
+		}
+	}
+}
+
+// prepareSQLite applies the SQLite settings held in config. database/sql cannot issue
+// a custom BEGIN, so the deferred transaction it opened is ended with ROLLBACK and
+// replaced by BEGIN <mode> on the same connection; the final COMMIT or ROLLBACK
+// issued through tx then applies to the replacement.
+func prepareSQLite(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	if config.SQLiteBusyTimeout > 0 {
+		// Round up: a busy_timeout of 0 turns the busy handler off
+		millis := (config.SQLiteBusyTimeout + time.Millisecond - 1) / time.Millisecond
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout = %d", millis)); err != nil {
+			return fmt.Errorf("failed to set busy timeout: %w", err)
+		}
+	}
+
+	if config.SQLiteBeginMode == "" {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, "ROLLBACK"); err != nil {
+		return fmt.Errorf("failed to end deferred transaction: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "BEGIN "+config.SQLiteBeginMode); err != nil {
+		return fmt.Errorf("failed to begin %s transaction: %w", config.SQLiteBeginMode, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+//go:build cgo
+
+package sqlxtx
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestExecuteContext_SQLiteImmediateCommits(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	ctx := context.Background()
+	err = ExecuteVoidContext(ctx, db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO items (id) VALUES (1)")
+		return err
+	}, WithSQLiteImmediate())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM items"); err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 committed row, got %d", count)
+	}
+}
+
+func TestExecuteContext_SQLiteBusyTimeoutRetriesBusy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlite3")
+
+	for i := 0; i < 4; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec("PRAGMA busy_timeout").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+	}
+
+	calls := 0
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		return nil, sqlite3.Error{Code: sqlite3.ErrBusy}
+	}, WithSQLiteBusyTimeout(time.Second))
+
+	if !IsSQLiteBusy(err) {
+		t.Errorf("expected SQLITE_BUSY, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 attempts, got %d", calls)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,153 @@
+package sqlxtx
+
+import (
+	"errors"
+	"reflect"
+)
+
+// PostgreSQL SQLSTATE codes used by the error classification helpers
+const (
+	SQLStateNotNullViolation     = "23502"
+	SQLStateForeignKeyViolation  = "23503"
+	SQLStateUniqueViolation      = "23505"
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+)
+
+// sqlStateUndefinedObject is reported by PostgreSQL for unrecognized configuration parameters
+const sqlStateUndefinedObject = "42704"
+
+// MySQL error numbers used by the error classification helpers
+const (
+	MySQLErrDupEntry           uint16 = 1062
+	MySQLErrBadNull            uint16 = 1048
+	MySQLErrLockDeadlock       uint16 = 1213
+	MySQLErrRowIsReferenced    uint16 = 1451
+	MySQLErrNoReferencedRow    uint16 = 1452
+	MySQLErrDupEntryWithKey    uint16 = 1586
+	MySQLErrRowIsReferencedOld uint16 = 1217
+	MySQLErrNoReferencedRowOld uint16 = 1216
+)
+
+// IsDeadlock reports whether err, or any error it wraps, is a database deadlock.
+// PostgreSQL (lib/pq, pgx or any error exposing SQLState()), MySQL and SQLite
+// (SQLITE_LOCKED) errors are recognized.
+func IsDeadlock(err error) bool {
+	if sqlState(err) == SQLStateDeadlockDetected {
+		return true
+	}
+	if number, ok := mysqlErrorNumber(err); ok && number == MySQLErrLockDeadlock {
+		return true
+	}
+	return isSQLiteDeadlock(err)
+}
+
+// IsSerializationFailure reports whether err, or any error it wraps, is a
+// serialization failure. MySQL reports serialization conflicts as deadlocks, so
+// only PostgreSQL and SQLite (SQLITE_BUSY_SNAPSHOT) are recognized.
+func IsSerializationFailure(err error) bool {
+	if sqlState(err) == SQLStateSerializationFailure {
+		return true
+	}
+	return isSQLiteSerializationFailure(err)
+}
+
+// IsUniqueViolation reports whether err, or any error it wraps, is a unique or
+// primary key constraint violation
+func IsUniqueViolation(err error) bool {
+	if sqlState(err) == SQLStateUniqueViolation {
+		return true
+	}
+	if number, ok := mysqlErrorNumber(err); ok {
+		return number == MySQLErrDupEntry || number == MySQLErrDupEntryWithKey
+	}
+	return isSQLiteUniqueViolation(err)
+}
+
+// IsForeignKeyViolation reports whether err, or any error it wraps, is a foreign key
+// constraint violation
+func IsForeignKeyViolation(err error) bool {
+	if sqlState(err) == SQLStateForeignKeyViolation {
+		return true
+	}
+	if number, ok := mysqlErrorNumber(err); ok {
+		switch number {
+		case MySQLErrRowIsReferenced, MySQLErrNoReferencedRow, MySQLErrRowIsReferencedOld, MySQLErrNoReferencedRowOld:
+			return true
+		}
+		return false
+	}
+	return isSQLiteForeignKeyViolation(err)
+}
+
+// IsNotNullViolation reports whether err, or any error it wraps, is a NOT NULL
+// constraint violation
+func IsNotNullViolation(err error) bool {
+	if sqlState(err) == SQLStateNotNullViolation {
+		return true
+	}
+	if number, ok := mysqlErrorNumber(err); ok {
+		return number == MySQLErrBadNull
+	}
+	return isSQLiteNotNullViolation(err)
+}
+
+// IsSQLiteBusy reports whether err is SQLite's SQLITE_BUSY, returned when another
+// connection holds a conflicting lock
+func IsSQLiteBusy(err error) bool {
+	return isSQLiteBusy(err)
+}
+
+// sqlState extracts the SQLSTATE code from err, or returns an empty string.
+// Both *pq.Error and pgx's *pgconn.PgError expose it through SQLState().
+func sqlState(err error) string {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+// mysqlErrorNumber extracts the Number of a go-sql-driver/mysql MySQLError from err
+func mysqlErrorNumber(err error) (uint16, bool) {
+	mysqlErr, ok := driverError(err, "github.com/go-sql-driver/mysql", "MySQLError")
+	if !ok {
+		return 0, false
+	}
+	number := mysqlErr.FieldByName("Number")
+	if number.Kind() != reflect.Uint16 {
+		return 0, false
+	}
+	return uint16(number.Uint()), true
+}
+
+// driverError finds the first error in err's tree, unwrapping like errors.As, whose
+// type is the struct typeName from pkgPath (or a pointer to it) and returns the struct.
+// Driver errors without accessor methods are recognized this way so the package does
+// not import, and thereby link in and register, every driver it knows about.
+func driverError(err error, pkgPath, typeName string) (reflect.Value, bool) {
+	for err != nil {
+		v := reflect.ValueOf(err)
+		if v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct && v.Type().PkgPath() == pkgPath && v.Type().Name() == typeName {
+			return v, true
+		}
+
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				if v, ok := driverError(e, pkgPath, typeName); ok {
+					return v, true
+				}
+			}
+			return reflect.Value{}, false
+		default:
+			return reflect.Value{}, false
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,57 @@
+package sqlxtx
+
+import "reflect"
+
+// SQLite result codes, mirroring the ErrNo and ErrNoExtended values of go-sqlite3
+const (
+	sqliteBusy              = 5
+	sqliteLocked            = 6
+	sqliteBusySnapshot      = sqliteBusy | 2<<8
+	sqliteConstraintForeign = 19 | 3<<8
+	sqliteConstraintNotNull = 19 | 5<<8
+	sqliteConstraintPrimary = 19 | 6<<8
+	sqliteConstraintUnique  = 19 | 8<<8
+)
+
+// sqliteCodes extracts the primary and extended result codes of a go-sqlite3 Error from err
+func sqliteCodes(err error) (code, extended int64, ok bool) {
+	sqliteErr, ok := driverError(err, "github.com/mattn/go-sqlite3", "Error")
+	if !ok {
+		return 0, 0, false
+	}
+	codeField, extendedField := sqliteErr.FieldByName("Code"), sqliteErr.FieldByName("ExtendedCode")
+	if codeField.Kind() != reflect.Int || extendedField.Kind() != reflect.Int {
+		return 0, 0, false
+	}
+	return codeField.Int(), extendedField.Int(), true
+}
+
+func isSQLiteBusy(err error) bool {
+	code, _, ok := sqliteCodes(err)
+	return ok && code == sqliteBusy
+}
+
+func isSQLiteDeadlock(err error) bool {
+	code, _, ok := sqliteCodes(err)
+	return ok && code == sqliteLocked
+}
+
+func isSQLiteSerializationFailure(err error) bool {
+	_, extended, ok := sqliteCodes(err)
+	return ok && extended == sqliteBusySnapshot
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	_, extended, ok := sqliteCodes(err)
+	return ok && (extended == sqliteConstraintUnique || extended == sqliteConstraintPrimary)
+}
+
+func isSQLiteForeignKeyViolation(err error) bool {
+	_, extended, ok := sqliteCodes(err)
+	return ok && extended == sqliteConstraintForeign
+}
+
+func isSQLiteNotNullViolation(err error) bool {
+	_, extended, ok := sqliteCodes(err)
+	return ok && extended == sqliteConstraintNotNull
+}
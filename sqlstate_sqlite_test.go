@@ -0,0 +1,42 @@
+//go:build cgo
+
+package sqlxtx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestSQLiteErrorClassification(t *testing.T) {
+	locked := fmt.Errorf("wrapped: %w", sqlite3.Error{Code: sqlite3.ErrLocked})
+	if !IsDeadlock(locked) {
+		t.Errorf("expected SQLITE_LOCKED to be a deadlock")
+	}
+
+	snapshot := sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusySnapshot}
+	if !IsSerializationFailure(snapshot) {
+		t.Errorf("expected SQLITE_BUSY_SNAPSHOT to be a serialization failure")
+	}
+	if IsDeadlock(snapshot) {
+		t.Errorf("expected SQLITE_BUSY_SNAPSHOT not to be a deadlock")
+	}
+}
+
+func TestSQLiteConstraintViolations(t *testing.T) {
+	unique := sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}
+	if !IsUniqueViolation(unique) {
+		t.Errorf("expected unique constraint to be a unique violation")
+	}
+
+	fk := sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}
+	if !IsForeignKeyViolation(fk) || IsUniqueViolation(fk) {
+		t.Errorf("expected foreign key constraint to be only a foreign key violation")
+	}
+
+	notNull := sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}
+	if !IsNotNullViolation(notNull) {
+		t.Errorf("expected not null constraint to be a not null violation")
+	}
+}
@@ -0,0 +1,77 @@
+package sqlxtx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestIsDeadlock(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "40P01"}, true},
+		{fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"}), true},
+		{&pq.Error{Code: "40001"}, false},
+		{&mysql.MySQLError{Number: 1213}, true},
+		{&mysql.MySQLError{Number: 1205}, false},
+		{errors.Join(errors.New("other"), fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1213})), true},
+		{&sqlStateError{"40P01"}, true},
+		{errors.New("deadlock"), false},
+	}
+
+	for _, c := range cases {
+		if got := IsDeadlock(c.err); got != c.want {
+			t.Errorf("IsDeadlock(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "40001"}, true},
+		{RollbackError{Err: errors.New("conn closed"), Cause: &pq.Error{Code: "40001"}}, true},
+		{&pq.Error{Code: "40P01"}, false},
+		{&mysql.MySQLError{Number: 1213}, false},
+	}
+
+	for _, c := range cases {
+		if got := IsSerializationFailure(c.err); got != c.want {
+			t.Errorf("IsSerializationFailure(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestConstraintViolations(t *testing.T) {
+	wrapped := func(err error) error { return fmt.Errorf("insert user: %w", err) }
+
+	cases := []struct {
+		name string
+		fn   func(error) bool
+		err  error
+		want bool
+	}{
+		{"pg unique", IsUniqueViolation, wrapped(&pq.Error{Code: "23505"}), true},
+		{"mysql unique", IsUniqueViolation, wrapped(&mysql.MySQLError{Number: 1062}), true},
+		{"pg fk not unique", IsUniqueViolation, &pq.Error{Code: "23503"}, false},
+		{"pg fk", IsForeignKeyViolation, wrapped(&pq.Error{Code: "23503"}), true},
+		{"mysql fk", IsForeignKeyViolation, &mysql.MySQLError{Number: 1452}, true},
+		{"mysql unique not fk", IsForeignKeyViolation, &mysql.MySQLError{Number: 1062}, false},
+		{"pg not null", IsNotNullViolation, wrapped(&pq.Error{Code: "23502"}), true},
+		{"mysql not null", IsNotNullViolation, &mysql.MySQLError{Number: 1048}, true},
+		{"plain error", IsNotNullViolation, errors.New("null"), false},
+	}
+
+	for _, c := range cases {
+		if got := c.fn(c.err); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"testing"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+// TxFixture inserts test data before a test and removes it afterwards. Setup and
+// teardown functions each run in their own transaction, in registration order.
+// The zero value is ready to use.
+type TxFixture struct {
+	setup    []sqlxtx.TxFunc[struct{}]
+	teardown []sqlxtx.TxFunc[struct{}]
+}
+
+// Setup adds a function that runs before the test
+func (f *TxFixture) Setup(fn sqlxtx.TxFunc[struct{}]) *TxFixture {
+	f.setup = append(f.setup, fn)
+	return f
+}
+
+// Teardown adds a function that runs after the test
+func (f *TxFixture) Teardown(fn sqlxtx.TxFunc[struct{}]) *TxFixture {
+	f.teardown = append(f.teardown, fn)
+	return f
+}
+
+// Run executes the setup functions against db, failing the test if one fails, and
+// registers the teardown functions with t.Cleanup so they run when the test ends,
+// even if it fails or panics. Teardown failures are reported with t.Errorf.
+func (f *TxFixture) Run(t testing.TB, db sqlxtx.Executer) {
+	t.Helper()
+
+	teardown := append([]sqlxtx.TxFunc[struct{}](nil), f.teardown...)
+	t.Cleanup(func() {
+		for _, fn := range teardown {
+			if _, err := sqlxtx.ExecuteContext(context.Background(), db, fn); err != nil {
+				t.Errorf("fixture teardown failed: %v", err)
+			}
+		}
+	})
+
+	for _, fn := range f.setup {
+		if _, err := sqlxtx.ExecuteContext(context.Background(), db, fn); err != nil {
+			t.Fatalf("fixture setup failed: %v", err)
+		}
+	}
+}
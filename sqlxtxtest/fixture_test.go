@@ -0,0 +1,44 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTxFixture_RunsTeardownAfterTest(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+	ctx := context.Background()
+
+	t.Run("with fixture", func(t *testing.T) {
+		var fixture TxFixture
+		fixture.
+			Setup(func(tx *sqlx.Tx) (struct{}, error) {
+				_, err := tx.ExecContext(ctx, "INSERT INTO users (name) VALUES ($1)", "fixture")
+				return struct{}{}, err
+			}).
+			Teardown(func(tx *sqlx.Tx) (struct{}, error) {
+				_, err := tx.ExecContext(ctx, "DELETE FROM users WHERE name = $1", "fixture")
+				return struct{}{}, err
+			})
+
+		fixture.Run(t, recorder)
+
+		if got := recorder.Queries(); len(got) != 1 {
+			t.Errorf("expected only the setup to have run, got %v", got)
+		}
+	})
+
+	want := []string{
+		"INSERT INTO users (name) VALUES ($1)",
+		"DELETE FROM users WHERE name = $1",
+	}
+	if got := recorder.Queries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected queries %v, got %v", want, got)
+	}
+	if !recorder.WasCommitted() {
+		t.Error("expected the teardown to be committed")
+	}
+}
@@ -0,0 +1,37 @@
+//go:build cgo
+
+package sqlxtxtest
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// inMemorySeq makes every in-memory database name unique
+var inMemorySeq atomic.Uint64
+
+// NewInMemoryDB opens a fresh SQLite in-memory database, applies each ddl string with
+// RunMigration and closes the database when the test ends. Every call returns an
+// independent database that is shared by all connections of its pool.
+// It requires cgo, like the sqlite3 driver it uses.
+func NewInMemoryDB(t testing.TB, ddl ...string) *sqlx.DB {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:sqlxtxtest_%d?mode=memory&cache=shared", inMemorySeq.Add(1))
+	db, err := sqlx.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("failed to open in-memory database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	for _, stmt := range ddl {
+		if err := RunMigration(db, stmt); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	return db
+}
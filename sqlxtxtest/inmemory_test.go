@@ -0,0 +1,55 @@
+//go:build cgo
+
+package sqlxtxtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestNewInMemoryDB(t *testing.T) {
+	db := NewInMemoryDB(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE)")
+	ctx := context.Background()
+
+	id, err := sqlxtx.ExecuteContext(ctx, db, func(tx *sqlx.Tx) (int64, error) {
+		res, err := tx.ExecContext(ctx, "INSERT INTO users (name) VALUES (?)", "John")
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if id != 1 {
+		t.Errorf("expected id 1, got %d", id)
+	}
+
+	txErr := errors.New("test error")
+	err = sqlxtx.ExecuteVoidContext(ctx, db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO users (name) VALUES (?)", "Jane"); err != nil {
+			return err
+		}
+		return txErr
+	})
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM users"); err != nil {
+		t.Fatalf("failed to count users: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected the rolled back insert to be discarded, got %d users", count)
+	}
+
+	if other := NewInMemoryDB(t); other.Get(&count, "SELECT COUNT(*) FROM users") == nil {
+		t.Error("expected a second in-memory database to be independent")
+	}
+}
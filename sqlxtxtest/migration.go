@@ -0,0 +1,16 @@
+package sqlxtxtest
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RunMigration executes ddl, which may hold several semicolon-separated statements
+// if the driver supports it (SQLite and PostgreSQL do)
+func RunMigration(db *sqlx.DB, ddl string) error {
+	if _, err := db.Exec(ddl); err != nil {
+		return fmt.Errorf("failed to run migration: %w", err)
+	}
+	return nil
+}
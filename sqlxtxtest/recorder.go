@@ -0,0 +1,206 @@
+// Package sqlxtxtest provides helpers for testing code built on sqlxtx.
+package sqlxtxtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxRecorder is an sqlxtx.Executer that records the statements run inside its
+// transactions instead of sending them to a database. Exec calls report zero rows
+// affected and queries return no rows.
+type TxRecorder struct {
+	db *sqlx.DB
+
+	mu         sync.Mutex
+	queries    []string
+	args       [][]any
+	committed  bool
+	rolledBack bool
+}
+
+// NewTxRecorder creates a TxRecorder reporting driverName, which decides the bind
+// style used by sqlx helpers and which driver-specific options are accepted
+func NewTxRecorder(driverName string) *TxRecorder {
+	r := &TxRecorder{}
+	r.db = sqlx.NewDb(sql.OpenDB(recorderConnector{r}), driverName)
+	return r
+}
+
+// DriverName returns the driver name given to NewTxRecorder
+func (r *TxRecorder) DriverName() string {
+	return r.db.DriverName()
+}
+
+// BeginTxx starts a recorded transaction
+func (r *TxRecorder) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return r.db.BeginTxx(ctx, opts)
+}
+
+// Queries returns every statement run so far, in execution order
+func (r *TxRecorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.queries...)
+}
+
+// Args returns the arguments of every statement run so far, matching Queries by index
+func (r *TxRecorder) Args() [][]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]any(nil), r.args...)
+}
+
+// WasCommitted reports whether the most recent transaction was committed
+func (r *TxRecorder) WasCommitted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.committed
+}
+
+// WasRolledBack reports whether the most recent transaction was rolled back
+func (r *TxRecorder) WasRolledBack() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rolledBack
+}
+
+// Reset clears everything recorded so far
+func (r *TxRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries, r.args = nil, nil
+	r.committed, r.rolledBack = false, false
+}
+
+func (r *TxRecorder) record(query string, args []driver.NamedValue) {
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, values)
+}
+
+func (r *TxRecorder) begin() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed, r.rolledBack = false, false
+}
+
+func (r *TxRecorder) finish(committed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed, r.rolledBack = committed, !committed
+}
+
+// The types below form a minimal database/sql driver that reports to a TxRecorder
+
+type recorderConnector struct{ r *TxRecorder }
+
+func (c recorderConnector) Connect(context.Context) (driver.Conn, error) {
+	return recorderConn(c), nil
+}
+
+func (c recorderConnector) Driver() driver.Driver {
+	return recorderDriver{}
+}
+
+type recorderDriver struct{}
+
+func (recorderDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+type recorderConn struct{ r *TxRecorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{r: c.r, query: query}, nil
+}
+
+func (c recorderConn) Close() error { return nil }
+
+func (c recorderConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c recorderConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.r.begin()
+	return recorderTx(c), nil
+}
+
+func (c recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args)
+	return recorderRows{}, nil
+}
+
+// CheckNamedValue accepts every argument as-is
+func (c recorderConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type recorderTx struct{ r *TxRecorder }
+
+func (tx recorderTx) Commit() error {
+	tx.r.finish(true)
+	return nil
+}
+
+func (tx recorderTx) Rollback() error {
+	tx.r.finish(false)
+	return nil
+}
+
+type recorderStmt struct {
+	r     *TxRecorder
+	query string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s recorderStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s recorderStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	s.r.record(s.query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (s recorderStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	s.r.record(s.query, args)
+	return recorderRows{}, nil
+}
+
+// CheckNamedValue accepts every argument as-is
+func (s recorderStmt) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type recorderRows struct{}
+
+func (recorderRows) Columns() []string              { return nil }
+func (recorderRows) Close() error                   { return nil }
+func (recorderRows) Next(dest []driver.Value) error { return io.EOF }
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
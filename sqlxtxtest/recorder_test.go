@@ -0,0 +1,69 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestTxRecorder_RecordsCommittedTransaction(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+	ctx := context.Background()
+
+	_, err := sqlxtx.ExecuteContext(ctx, recorder, func(tx *sqlx.Tx) (any, error) {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO users (name) VALUES ($1)", "John"); err != nil {
+			return nil, err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE users SET active = $1 WHERE name = $2", true, "John")
+		return nil, err
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	wantQueries := []string{
+		"INSERT INTO users (name) VALUES ($1)",
+		"UPDATE users SET active = $1 WHERE name = $2",
+	}
+	if got := recorder.Queries(); !reflect.DeepEqual(got, wantQueries) {
+		t.Errorf("expected queries %v, got %v", wantQueries, got)
+	}
+	wantArgs := [][]any{{"John"}, {true, "John"}}
+	if got := recorder.Args(); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("expected args %v, got %v", wantArgs, got)
+	}
+	if !recorder.WasCommitted() || recorder.WasRolledBack() {
+		t.Error("expected the transaction to be committed")
+	}
+}
+
+func TestTxRecorder_RecordsRollback(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+	ctx := context.Background()
+
+	txErr := errors.New("test error")
+	err := sqlxtx.ExecuteVoidContext(ctx, recorder, func(tx *sqlx.Tx) error {
+		var name string
+		if err := tx.GetContext(ctx, &name, "SELECT name FROM users WHERE id = $1", 1); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected no rows, got %v", err)
+		}
+		return txErr
+	})
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+	if recorder.WasCommitted() || !recorder.WasRolledBack() {
+		t.Error("expected the transaction to be rolled back")
+	}
+	if got := recorder.Queries(); len(got) != 1 {
+		t.Errorf("expected one recorded query, got %v", got)
+	}
+}
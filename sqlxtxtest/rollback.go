@@ -0,0 +1,42 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+// WrapInRollback begins a transaction on db, passes it to fn and rolls it back when
+// the test ends, so nothing fn writes outlives the test. Only transaction options
+// (isolation level, read-only) are supported: any other option, such as a hook or a
+// session setting, fails the test with t.Fatalf rather than being silently ignored.
+// A failed rollback is reported with t.Errorf.
+func WrapInRollback(t testing.TB, db sqlxtx.Executer, fn func(tx *sqlx.Tx), options ...sqlxtx.ConfigOption) {
+	t.Helper()
+
+	var config sqlxtx.Config
+	for _, option := range options {
+		option(&config)
+	}
+	unsupported := config
+	unsupported.TxOptions = nil
+	if !reflect.ValueOf(unsupported).IsZero() {
+		t.Fatalf("WrapInRollback only supports transaction options such as isolation level and read-only")
+	}
+
+	tx, err := db.BeginTxx(context.Background(), config.TxOptions)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := tx.Rollback(); err != nil {
+			t.Errorf("failed to roll back test transaction: %v", err)
+		}
+	})
+
+	fn(tx)
+}
@@ -0,0 +1,67 @@
+package sqlxtxtest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestWrapInRollback(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+
+	t.Run("wrapped", func(t *testing.T) {
+		WrapInRollback(t, recorder, func(tx *sqlx.Tx) {
+			if _, err := tx.Exec("INSERT INTO users (name) VALUES ($1)", "John"); err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		}, sqlxtx.WithReadCommitted())
+
+		if recorder.WasRolledBack() {
+			t.Error("expected the rollback to wait until the test ends")
+		}
+	})
+
+	if !recorder.WasRolledBack() || recorder.WasCommitted() {
+		t.Error("expected the transaction to be rolled back after the test")
+	}
+	if got := recorder.Queries(); len(got) != 1 {
+		t.Errorf("expected one recorded query, got %v", got)
+	}
+}
+
+// fatalRecorder captures Fatalf instead of stopping the test
+type fatalRecorder struct {
+	testing.TB
+	fatal string
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...any) {
+	r.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestWrapInRollback_RejectsUnsupportedOptions(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+	tb := &fatalRecorder{TB: t}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WrapInRollback(tb, recorder, func(tx *sqlx.Tx) {
+			t.Error("expected fn not to run")
+		}, sqlxtx.WithReadCommitted(), sqlxtx.WithStatementTimeout(time.Second))
+	}()
+	<-done
+
+	if tb.fatal == "" {
+		t.Error("expected WrapInRollback to fail the test for an unsupported option")
+	}
+	if recorder.WasRolledBack() || recorder.WasCommitted() {
+		t.Error("expected no transaction to be started")
+	}
+}
@@ -0,0 +1,70 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+// TxCallRecord describes one transaction attempt seen by a TxSpy
+type TxCallRecord struct {
+	DriverName string
+	Isolation  sql.IsolationLevel
+	ReadOnly   bool
+	Committed  bool
+	Err        error // the error returned by the attempt; set after a commit when a commit hook fails
+}
+
+// TxSpy records every transaction attempt made with its Option, working at the
+// sqlxtx level rather than the driver level. It is safe for concurrent use.
+type TxSpy struct {
+	mu    sync.Mutex
+	calls []TxCallRecord
+}
+
+// NewTxSpy creates an empty TxSpy
+func NewTxSpy() *TxSpy {
+	return &TxSpy{}
+}
+
+// Option returns the ConfigOption that reports to the spy. Pass it to the calls under
+// test, or install it for every call with sqlxtx.SetDefaultOptions.
+func (s *TxSpy) Option() sqlxtx.ConfigOption {
+	return sqlxtx.WithObserver(func(ctx context.Context, info sqlxtx.TxInfo) (context.Context, func(committed bool, err error)) {
+		return ctx, func(committed bool, err error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.calls = append(s.calls, TxCallRecord{
+				DriverName: info.DriverName,
+				Isolation:  info.IsolationLevel(),
+				ReadOnly:   info.ReadOnly(),
+				Committed:  committed,
+				Err:        err,
+			})
+		}
+	})
+}
+
+// Calls returns a record per transaction attempt, in completion order. A retried
+// transaction produces one record per attempt.
+func (s *TxSpy) Calls() []TxCallRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TxCallRecord(nil), s.calls...)
+}
+
+// CallCount returns the number of recorded attempts
+func (s *TxSpy) CallCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls)
+}
+
+// Reset clears the recorded attempts
+func (s *TxSpy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = nil
+}
@@ -0,0 +1,50 @@
+package sqlxtxtest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	sqlxtx "github.com/huangc28/sqlx-tx"
+)
+
+func TestTxSpy_RecordsCalls(t *testing.T) {
+	recorder := NewTxRecorder("postgres")
+	spy := NewTxSpy()
+	ctx := context.Background()
+
+	_, err := sqlxtx.ExecuteContext(ctx, recorder, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, sqlxtx.WithSerializable(), spy.Option())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	txErr := errors.New("test error")
+	_, err = sqlxtx.ExecuteContext(ctx, recorder, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	}, sqlxtx.WithReadOnly(), spy.Option())
+	if !errors.Is(err, txErr) {
+		t.Fatalf("expected test error, got %v", err)
+	}
+
+	calls := spy.Calls()
+	if len(calls) != 2 || spy.CallCount() != 2 {
+		t.Fatalf("expected 2 calls, got %v", calls)
+	}
+
+	if first := calls[0]; !first.Committed || first.Isolation != sql.LevelSerializable || first.ReadOnly || first.DriverName != "postgres" {
+		t.Errorf("unexpected first call %+v", first)
+	}
+	if second := calls[1]; second.Committed || !second.ReadOnly || !errors.Is(second.Err, txErr) {
+		t.Errorf("unexpected second call %+v", second)
+	}
+
+	spy.Reset()
+	if spy.CallCount() != 0 {
+		t.Error("expected no calls after Reset")
+	}
+}
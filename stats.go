@@ -0,0 +1,88 @@
+package sqlxtx
+
+import (
+	"context"
+	"time"
+)
+
+// Transaction outcomes reported in TxStats.Outcome
+const (
+	OutcomeCommitted  = "committed"
+	OutcomeRolledBack = "rolledback"
+	OutcomePanicked   = "panicked"
+)
+
+// TxStats holds timing data for a single Execute call. Timestamps and durations
+// describe the last attempt; Outcome is empty if the transaction never began.
+type TxStats struct {
+	BeganAt        time.Time
+	CommittedAt    time.Time
+	RolledBackAt   time.Time
+	BeginDuration  time.Duration
+	CommitDuration time.Duration
+	TotalDuration  time.Duration
+	Attempts       int
+	Retried        bool
+	Outcome        string
+}
+
+// WithStats populates stats while the transaction runs. Because the struct is owned
+// by the caller it remains readable when the transaction function panics.
+func WithStats(stats *TxStats) ConfigOption {
+	return func(c *Config) {
+		c.Stats = stats
+	}
+}
+
+// ExecuteWithStats runs a function within a transaction and returns its timing data
+func ExecuteWithStats[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options ...ConfigOption) (T, TxStats, error) {
+	var stats TxStats
+	options = append(options[:len(options):len(options)], WithStats(&stats))
+	result, err := ExecuteContext(ctx, db, txFunc, options...)
+	return result, stats, err
+}
+
+// The record methods are no-ops on a nil receiver so callers need not check for WithStats
+
+func (s *TxStats) recordAttempt(attempt int) {
+	if s == nil {
+		return
+	}
+	s.Attempts = attempt
+	s.Retried = attempt > 1
+	s.Outcome = ""
+	s.CommittedAt, s.RolledBackAt = time.Time{}, time.Time{}
+	s.CommitDuration = 0
+}
+
+func (s *TxStats) recordBegin(start time.Time) {
+	if s == nil {
+		return
+	}
+	s.BeganAt = start
+	s.BeginDuration = time.Since(start)
+}
+
+func (s *TxStats) recordCommit(start time.Time) {
+	if s == nil {
+		return
+	}
+	s.CommittedAt = time.Now()
+	s.CommitDuration = s.CommittedAt.Sub(start)
+	s.Outcome = OutcomeCommitted
+}
+
+func (s *TxStats) recordRollback(outcome string) {
+	if s == nil {
+		return
+	}
+	s.RolledBackAt = time.Now()
+	s.Outcome = outcome
+}
+
+func (s *TxStats) recordTotal(start time.Time) {
+	if s == nil {
+		return
+	}
+	s.TotalDuration = time.Since(start)
+}
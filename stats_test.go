@@ -0,0 +1,101 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteWithStats_CommittedAfterRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	calls := 0
+	_, stats, err := ExecuteWithStats(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, &sqlStateError{"40001"}
+		}
+		return nil, nil
+	}, WithRetry(2, nil))
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stats.Outcome != OutcomeCommitted {
+		t.Errorf("expected outcome %q, got %q", OutcomeCommitted, stats.Outcome)
+	}
+	if stats.Attempts != 2 || !stats.Retried {
+		t.Errorf("expected 2 attempts with retry, got %d (retried=%v)", stats.Attempts, stats.Retried)
+	}
+	if stats.BeganAt.IsZero() || stats.CommittedAt.IsZero() || !stats.RolledBackAt.IsZero() {
+		t.Errorf("unexpected timestamps: %+v", stats)
+	}
+	if stats.TotalDuration <= 0 {
+		t.Errorf("expected positive total duration, got %v", stats.TotalDuration)
+	}
+}
+
+func TestExecuteWithStats_RolledBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	_, stats, err := ExecuteWithStats(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, errors.New("test error")
+	})
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if stats.Outcome != OutcomeRolledBack || stats.RolledBackAt.IsZero() {
+		t.Errorf("expected rolled back stats, got %+v", stats)
+	}
+}
+
+func TestWithStats_Panicked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	var stats TxStats
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic, but function did not panic")
+		}
+		if stats.Outcome != OutcomePanicked {
+			t.Errorf("expected outcome %q, got %q", OutcomePanicked, stats.Outcome)
+		}
+	}()
+
+	ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		panic("test panic")
+	}, WithStats(&stats))
+}
@@ -0,0 +1,46 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ExecuteSQL runs fn within a transaction on a plain *sql.DB, for code that works with
+// database/sql types only. It behaves like ExecuteContext; the driver name used for
+// option validation is inferred from db.Driver() for lib/pq, pgx, go-sql-driver/mysql,
+// mattn/go-sqlite3 and modernc.org/sqlite. For other drivers it is left empty, so
+// driver-specific options are applied without being checked against the database.
+func ExecuteSQL[T any](ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) (T, error), options ...ConfigOption) (T, error) {
+	return ExecuteContext(ctx, sqlx.NewDb(db, driverNameOf(db.Driver())), func(tx *sqlx.Tx) (T, error) {
+		return fn(tx.Tx)
+	}, options...)
+}
+
+// driverNameOf returns the registered name of well-known drivers, or an empty string.
+// Drivers are matched by package path so that none of them has to be imported.
+func driverNameOf(d driver.Driver) string {
+	t := reflect.TypeOf(d)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.PkgPath() {
+	case "github.com/lib/pq":
+		return "postgres"
+	case "github.com/jackc/pgx/v5/stdlib", "github.com/jackc/pgx/v4/stdlib":
+		return "pgx"
+	case "github.com/go-sql-driver/mysql":
+		return "mysql"
+	case "github.com/mattn/go-sqlite3":
+		return "sqlite3"
+	case "modernc.org/sqlite":
+		return "sqlite"
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestExecuteSQL_Commit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectCommit()
+
+	id, err := ExecuteSQL(context.Background(), db, func(tx *sql.Tx) (int64, error) {
+		res, err := tx.Exec("INSERT INTO users (name) VALUES ($1)", "alice")
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 7 {
+		t.Errorf("expected id 7, got %d", id)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteSQL_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	boom := errors.New("boom")
+	_, err = ExecuteSQL(context.Background(), db, func(tx *sql.Tx) (struct{}, error) {
+		return struct{}{}, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDriverNameOf(t *testing.T) {
+	if got := driverNameOf(&pq.Driver{}); got != "postgres" {
+		t.Errorf("expected postgres, got %q", got)
+	}
+	if got := driverNameOf(mysql.MySQLDriver{}); got != "mysql" {
+		t.Errorf("expected mysql, got %q", got)
+	}
+	if got := driverNameOf(&sqlite3.SQLiteDriver{}); got != "sqlite3" {
+		t.Errorf("expected sqlite3, got %q", got)
+	}
+}
+
+func TestExecuteSQL_UnknownDriverAppliesSQLiteOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("BEGIN IMMEDIATE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = ExecuteSQL(context.Background(), db, func(tx *sql.Tx) (struct{}, error) {
+		return struct{}{}, nil
+	}, WithSQLiteImmediate())
+	if err != nil {
+		t.Fatalf("expected SQLite options to pass on an unrecognized driver, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
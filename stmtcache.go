@@ -0,0 +1,146 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txCaches maps each running transaction to its statement cache
+var txCaches sync.Map
+
+// WithStatementCache keeps a TxCache for the transaction so repeated queries are
+// prepared only once. Fetch it inside the transaction function with TxCacheFor.
+// Cached statements are closed before the transaction commits or rolls back.
+func WithStatementCache() ConfigOption {
+	return func(c *Config) {
+		c.StatementCache = true
+	}
+}
+
+// TxCache wraps a transaction and prepares each distinct query once, reusing the
+// statement for later calls. It is safe for concurrent use.
+type TxCache struct {
+	*sqlx.Tx
+
+	mu    sync.Mutex
+	stmts map[string]*sqlx.Stmt
+}
+
+// NewTxCache wraps tx in a statement cache. Call Close before the transaction ends.
+func NewTxCache(tx *sqlx.Tx) *TxCache {
+	return &TxCache{Tx: tx, stmts: make(map[string]*sqlx.Stmt)}
+}
+
+// TxCacheFor returns the statement cache that WithStatementCache opened for tx
+func TxCacheFor(tx *sqlx.Tx) (*TxCache, bool) {
+	cache, ok := txCaches.Load(tx)
+	if !ok {
+		return nil, false
+	}
+	return cache.(*TxCache), true
+}
+
+// openTxCache registers a cache for tx and returns a function that closes and unregisters it
+func openTxCache(tx *sqlx.Tx) func() {
+	cache := NewTxCache(tx)
+	txCaches.Store(tx, cache)
+	return func() {
+		txCaches.Delete(tx)
+		_ = cache.Close()
+	}
+}
+
+// Stmt returns the cached statement for query, preparing it on first use
+func (c *TxCache) Stmt(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if stmt, ok := c.stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := c.Tx.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// Close closes every cached statement and returns the first error
+func (c *TxCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	return firstErr
+}
+
+func (c *TxCache) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (c *TxCache) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// QueryRowContext falls back to an unprepared query if preparing fails, so the
+// error is reported by Scan as usual
+func (c *TxCache) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return c.Tx.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+func (c *TxCache) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryxContext(ctx, args...)
+}
+
+// QueryRowxContext falls back to an unprepared query if preparing fails, so the
+// error is reported by Scan as usual
+func (c *TxCache) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return c.Tx.QueryRowxContext(ctx, query, args...)
+	}
+	return stmt.QueryRowxContext(ctx, args...)
+}
+
+func (c *TxCache) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	return stmt.GetContext(ctx, dest, args...)
+}
+
+func (c *TxCache) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	stmt, err := c.Stmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	return stmt.SelectContext(ctx, dest, args...)
+}
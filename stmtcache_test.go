@@ -0,0 +1,82 @@
+package sqlxtx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_StatementCachePreparesOnce(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	query := "INSERT INTO users (name) VALUES ($1)"
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs("John").WillReturnResult(sqlmock.NewResult(1, 1))
+	prep.ExpectExec().WithArgs("Jane").WillReturnResult(sqlmock.NewResult(2, 1))
+	prep.WillBeClosed()
+	mock.ExpectCommit()
+
+	var captured *sqlx.Tx
+	ctx := context.Background()
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		captured = tx
+		cache, ok := TxCacheFor(tx)
+		if !ok {
+			t.Fatal("expected a statement cache for the transaction")
+		}
+		for _, name := range []string{"John", "Jane"} {
+			if _, err := cache.ExecContext(ctx, query, name); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}, WithStatementCache())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, ok := TxCacheFor(captured); ok {
+		t.Error("expected the cache to be discarded after commit")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTxCacheFor_WithoutOption(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		if _, ok := TxCacheFor(tx); ok {
+			t.Error("expected no statement cache without WithStatementCache")
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -3,7 +3,12 @@ package sqlxtx
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -11,15 +16,131 @@ import (
 // TxFunc defines a function type that operates within a database transaction
 type TxFunc[T any] func(tx *sqlx.Tx) (T, error)
 
+// ContextTxFunc is a TxFunc that also receives the context of the transaction attempt,
+// see ExecuteWithContextFunc
+type ContextTxFunc[T any] func(ctx context.Context, tx *sqlx.Tx) (T, error)
+
 // Config holds configuration options for transaction execution
 type Config struct {
-	TxOptions     *sql.TxOptions
-	DeallocateAll bool // PostgreSQL specific
+	Version               int    // see ConfigVersion and MigrateConfig
+	DriverName            string // set by ExecuteContext from the database handle
+	TxOptions             *sql.TxOptions
+	DeallocateAll         bool // PostgreSQL specific
+	MaxAttempts           int
+	Backoff               BackoffFunc
+	Jitter                float64
+	RetryPredicate        RetryPredicate
+	AttemptContext        func(ctx context.Context) context.Context
+	CustomBegin           BeginFunc
+	CustomCommit          func(ctx context.Context, tx *sqlx.Tx) error
+	OnError               func(ctx context.Context, err error) (shouldRollback bool)
+	Progress              ProgressReporter
+	CreatedAtColumn       string
+	UpdatedAtColumn       string
+	OptimisticLocking     bool
+	SavepointName         string
+	OnBegin               []func(ctx context.Context, tx *sqlx.Tx) error
+	OnCommit              []func()
+	OnRollback            []func(err error)
+	PostCommitActions     []func(ctx context.Context) error
+	MaintenanceStatements []string
+	PanicHandlers         []func(ctx context.Context, panicVal any)
+	Timeout               time.Duration
+	MaxConnectionWait     time.Duration
+	Observers             []Observer
+	Propagation           Propagation
+	WatchThreshold        time.Duration
+	Watcher               func(duration time.Duration, ctx context.Context)
+	Stats                 *TxStats
+	OutboxFallback        any // func(T, error), see WithOutboxFallback
+	SyncCompensation      bool
+	IdempotencyKey        string
+	IdempotencyStore      IdempotencyStore
+	CircuitBreaker        CircuitBreaker
+	RateLimiter           RateLimiter
+	QueryInterceptors     []func(ctx context.Context, query string) string
+	StatementCache        bool
+	ErrorMapper           ErrorMapper
+	ExplainLogger         *slog.Logger
+	ExplainThreshold      time.Duration
+	MinRowsAffected       *int64
+	MaxRowsAffected       *int64
+
+	// PostgreSQL session settings
+	StatementTimeout         time.Duration
+	LockTimeout              time.Duration
+	IdleInTransactionTimeout time.Duration
+	AdvisoryLocks            []int64
+	AdvisoryTryLocks         []int64
+	SearchPath               []string
+	SessionVariables         map[string]string
+	ApplicationName          string
+	SynchronousCommit        string
+	WorkMem                  string
+	Role                     string
+	DeallocateNames          []string
+	DeallocatePatterns       []string
+	RequiredExtensions       []string
+	LogParameters            bool
+	LogParameterMaxLength    int
+	RefreshViews             []string
+	Notifications            []Notification
+
+	// MySQL session settings
+	MySQLDeallocate []string
+	MySQLCharset    string
+	MySQLTimezone   string
+
+	// SQLite settings
+	SQLiteBeginMode   string
+	SQLiteBusyTimeout time.Duration
+
+	// Connection keepalive, see WithHeartbeat
+	HeartbeatInterval time.Duration
 }
 
 // ConfigOption is a function that modifies Config
 type ConfigOption func(*Config)
 
+// Clone returns a deep copy of c that can be modified without affecting c. TxOptions,
+// slices, maps and the rows affected limits are copied; interface values such as
+// Stats, IdempotencyStore or the circuit breaker are shared, as are the hook functions.
+func (c *Config) Clone() *Config {
+	clone := *c
+	if c.TxOptions != nil {
+		txOptions := *c.TxOptions
+		clone.TxOptions = &txOptions
+	}
+	if c.MinRowsAffected != nil {
+		n := *c.MinRowsAffected
+		clone.MinRowsAffected = &n
+	}
+	if c.MaxRowsAffected != nil {
+		n := *c.MaxRowsAffected
+		clone.MaxRowsAffected = &n
+	}
+
+	clone.OnBegin = slices.Clone(c.OnBegin)
+	clone.OnCommit = slices.Clone(c.OnCommit)
+	clone.OnRollback = slices.Clone(c.OnRollback)
+	clone.PostCommitActions = slices.Clone(c.PostCommitActions)
+	clone.MaintenanceStatements = slices.Clone(c.MaintenanceStatements)
+	clone.PanicHandlers = slices.Clone(c.PanicHandlers)
+	clone.Observers = slices.Clone(c.Observers)
+	clone.QueryInterceptors = slices.Clone(c.QueryInterceptors)
+	clone.AdvisoryLocks = slices.Clone(c.AdvisoryLocks)
+	clone.AdvisoryTryLocks = slices.Clone(c.AdvisoryTryLocks)
+	clone.SearchPath = slices.Clone(c.SearchPath)
+	clone.SessionVariables = maps.Clone(c.SessionVariables)
+	clone.DeallocateNames = slices.Clone(c.DeallocateNames)
+	clone.DeallocatePatterns = slices.Clone(c.DeallocatePatterns)
+	clone.RequiredExtensions = slices.Clone(c.RequiredExtensions)
+	clone.RefreshViews = slices.Clone(c.RefreshViews)
+	clone.Notifications = slices.Clone(c.Notifications)
+	clone.MySQLDeallocate = slices.Clone(c.MySQLDeallocate)
+	return &clone
+}
+
 // WithDeallocateAll enables PostgreSQL-specific prepared statement cleanup
 func WithDeallocateAll() ConfigOption {
 	return func(c *Config) {
@@ -44,6 +165,26 @@ func WithIsolationLevel(level sql.IsolationLevel) ConfigOption {
 	}
 }
 
+// WithSerializable sets the transaction isolation level to serializable
+func WithSerializable() ConfigOption {
+	return WithIsolationLevel(sql.LevelSerializable)
+}
+
+// WithRepeatableRead sets the transaction isolation level to repeatable read
+func WithRepeatableRead() ConfigOption {
+	return WithIsolationLevel(sql.LevelRepeatableRead)
+}
+
+// WithReadCommitted sets the transaction isolation level to read committed
+func WithReadCommitted() ConfigOption {
+	return WithIsolationLevel(sql.LevelReadCommitted)
+}
+
+// WithReadUncommitted sets the transaction isolation level to read uncommitted
+func WithReadUncommitted() ConfigOption {
+	return WithIsolationLevel(sql.LevelReadUncommitted)
+}
+
 // WithReadOnly sets the transaction to read-only mode
 func WithReadOnly() ConfigOption {
 	return func(c *Config) {
@@ -54,48 +195,321 @@ func WithReadOnly() ConfigOption {
 	}
 }
 
-// Execute runs a function within a transaction with default settings
-func Execute[T any](db *sqlx.DB, txFunc TxFunc[T]) (T, error) {
-	return ExecuteContext(context.Background(), db, txFunc)
+// WithTimeout bounds each transaction attempt with a client-side deadline.
+// If ctx already has an earlier deadline, that deadline wins. When retrying, every
+// attempt gets a new deadline derived from the caller's ctx, so time spent by an
+// earlier attempt does not shorten the next one; only ctx's own deadline spans attempts.
+func WithTimeout(d time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.Timeout = d
+	}
+}
+
+// Execute runs a function within a transaction using context.Background() and optional configuration
+func Execute[T any](db Executer, txFunc TxFunc[T], options ...ConfigOption) (T, error) {
+	return ExecuteContext(context.Background(), db, txFunc, options...)
 }
 
 // ExecuteContext runs a function within a transaction with context support and optional configuration
-func ExecuteContext[T any](ctx context.Context, db *sqlx.DB, txFunc TxFunc[T], options ...ConfigOption) (result T, err error) {
-	// Apply configuration options
-	config := &Config{}
-	for _, option := range options {
-		option(config)
-	}
+func ExecuteContext[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options ...ConfigOption) (T, error) {
+	return execute(ctx, db, entryContext, func(*Config) (ContextTxFunc[T], error) {
+		return func(_ context.Context, tx *sqlx.Tx) (T, error) { return txFunc(tx) }, nil
+	}, options)
+}
+
+// ExecuteWithContextFunc is like ExecuteContext, but fn also receives the context of
+// the transaction attempt. That context is derived from ctx and carries what the
+// transaction adds to it, such as the WithTimeout deadline, the observers' values and
+// the WithProgressCallback reporter used by ReportProgress.
+func ExecuteWithContextFunc[T any](ctx context.Context, db Executer, fn ContextTxFunc[T], options ...ConfigOption) (T, error) {
+	return execute(ctx, db, entryContextFunc, func(*Config) (ContextTxFunc[T], error) { return fn, nil }, options)
+}
+
+// entryPoint identifies the function a transaction was started through, for options
+// that only take effect through one of them
+type entryPoint int
 
-	tx, err := db.BeginTxx(ctx, config.TxOptions)
+const (
+	entryContext entryPoint = iota
+	entryContextFunc
+	entryIntercepted
+	entryWithResult
+	entryLazy
+	entryCompensation
+	entryOutbox
+)
+
+// execute implements ExecuteContext. bind receives the Config built from options and
+// returns the function to run, so entry points that wrap the function read their
+// settings from the same Config instead of applying the options a second time. An
+// error from bind is returned without starting a transaction.
+func execute[T any](ctx context.Context, db Executer, entry entryPoint, bind func(config *Config) (ContextTxFunc[T], error), options []ConfigOption) (result T, err error) {
+	// Apply package defaults, then call-site options
+	config := newConfig(options)
+
+	config.DriverName = db.DriverName()
+	if err = config.Validate(); err != nil {
+		return result, err
+	}
+	if err = config.validateEntryPoint(entry); err != nil {
+		return result, err
+	}
+	txFunc, err := bind(config)
 	if err != nil {
-		return result, fmt.Errorf("failed to begin transaction: %w", err)
+		return result, err
 	}
 
-	// PostgreSQL-specific cleanup (optional)
-	if config.DeallocateAll {
-		if _, deallocErr := tx.ExecContext(ctx, "DEALLOCATE ALL"); deallocErr != nil {
-			_ = tx.Rollback()
-			return result, fmt.Errorf("failed to deallocate prepared statements: %w", deallocErr)
+	// A joined transaction runs with the caller's context
+	joined := func(tx *sqlx.Tx) (T, error) { return txFunc(ctx, tx) }
+	if result, handled, err := executePropagated(ctx, config, joined, options); handled {
+		return result, err
+	}
+
+	if result, found, err := checkIdempotency[T](ctx, config); found || err != nil {
+		return result, err
+	}
+
+	start := time.Now()
+	defer config.Stats.recordTotal(start)
+
+	for attempt := 1; ; attempt++ {
+		config.Stats.recordAttempt(attempt)
+		attemptCtx := ctx
+		if config.AttemptContext != nil {
+			attemptCtx = config.AttemptContext(ctx)
+		}
+		var committed bool
+		result, committed, err = executeOnce(attemptCtx, db, config, txFunc)
+		if committed || !shouldRetry(ctx, config, attempt, err) {
+			if err == nil {
+				err = storeIdempotency(ctx, config, result)
+			}
+			if err == nil {
+				err = runMaintenance(ctx, db, config, result)
+			}
+			return result, err
+		}
+
+		if waitErr := waitBackoff(ctx, config, attempt); waitErr != nil {
+			return result, err
+		}
+	}
+}
+
+// executeOnce runs a single transaction attempt. committed reports whether the
+// transaction was committed, which WithOnError allows even when err is not nil.
+func executeOnce[T any](ctx context.Context, db Executer, config *Config, txFunc ContextTxFunc[T]) (result T, committed bool, err error) {
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	// Rate-limited and circuit-broken attempts never touch the database, so observers
+	// are not notified of them
+	if config.RateLimiter != nil {
+		if err = config.RateLimiter.Wait(ctx); err != nil {
+			return result, committed, err
 		}
 	}
 
+	if config.CircuitBreaker != nil && !config.CircuitBreaker.Allow() {
+		return result, committed, ErrCircuitOpen
+	}
+
+	var panicErr error
+	var commitOnError bool
+	info := TxInfo{DriverName: config.DriverName, TxOptions: config.TxOptions}
+	ctx, finish := startObservers(ctx, info, config)
+	defer func() {
+		if panicErr != nil {
+			finish(false, panicErr)
+		} else {
+			finish(committed, err)
+		}
+	}()
+
+	beginStart := time.Now()
+	tx, release, err := beginTx(ctx, db, config)
+	if err != nil {
+		config.recordBreaker(false)
+		return result, committed, BeginError{Err: err}
+	}
+	defer release()
+	config.Stats.recordBegin(beginStart)
+
+	activeTxs.Store(tx, info)
+	defer activeTxs.Delete(tx)
+	ctx = withProgress(ctx, config)
+	defer trackAuditColumns(tx, config)()
+	defer startTxWatcher(ctx, config)()
+
 	defer func() {
 		if p := recover(); p != nil {
+			panicErr = fmt.Errorf("transaction panicked: %v", p)
 			_ = tx.Rollback()
+			config.Stats.recordRollback(OutcomePanicked)
+			config.recordBreaker(false)
+			_ = runRollbackHooks(config, panicErr)
+			runPanicHandlers(ctx, config, p)
 			panic(p)
-		} else if err != nil {
+		} else if err != nil && !commitOnError {
 			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				err = fmt.Errorf("transaction rollback failed: %v (original error: %w)", rollbackErr, err)
+				err = RollbackError{Err: rollbackErr, Cause: err}
+			}
+			config.Stats.recordRollback(OutcomeRolledBack)
+			config.recordBreaker(false)
+			if hookErr := runRollbackHooks(config, err); hookErr != nil {
+				err = fmt.Errorf("%w (rollback hook error: %v)", err, hookErr)
 			}
 		} else {
-			err = tx.Commit()
-			if err != nil {
-				err = fmt.Errorf("failed to commit transaction: %w", err)
+			txErr := err
+			commitStart := time.Now()
+			if commitErr := commit(ctx, tx, config); commitErr != nil {
+				err = CommitError{Err: commitErr, Cause: txErr}
+				config.Stats.recordRollback(OutcomeRolledBack)
+				config.recordBreaker(false)
+				if hookErr := runRollbackHooks(config, err); hookErr != nil {
+					err = fmt.Errorf("%w (rollback hook error: %v)", err, hookErr)
+				}
+			} else {
+				config.Stats.recordCommit(commitStart)
+				config.recordBreaker(true)
+				err = runAfterCommit(ctx, db, config)
+				committed = true
+				if txErr != nil {
+					if err != nil {
+						txErr = fmt.Errorf("%w (commit hook error: %v)", txErr, err)
+					}
+					err = txErr
+				}
 			}
 		}
 	}()
 
-	result, err = txFunc(tx)
-	return result, err
+	if err = prepareTx(ctx, tx, config); err != nil {
+		return result, committed, err
+	}
+
+	if config.StatementCache {
+		defer openTxCache(tx)()
+	}
+
+	stopHeartbeat := startHeartbeat(ctx, tx, config)
+	defer stopHeartbeat()
+	result, err = txFunc(ctx, tx)
+	if heartbeatErr := stopHeartbeat(); heartbeatErr != nil && err == nil {
+		err = heartbeatErr
+	}
+	if err != nil && config.ErrorMapper != nil {
+		err = config.ErrorMapper.Map(err)
+	}
+	if err != nil && config.OnError != nil {
+		commitOnError = !config.OnError(ctx, err)
+	}
+	if err == nil || commitOnError {
+		if notifyErr := notifyPostgres(ctx, tx, config); notifyErr != nil {
+			if commitOnError {
+				// Keep the error WithOnError chose to commit with
+				notifyErr = errors.Join(err, notifyErr)
+			}
+			err, commitOnError = notifyErr, false
+		}
+	}
+	return result, committed, err
+}
+
+// prepareTx runs the configured setup statements before the user function is called
+func prepareTx(ctx context.Context, tx *sqlx.Tx, config *Config) error {
+	if err := checkDriverSupport(tx.DriverName(), config); err != nil {
+		return err
+	}
+
+	if err := prepareSQLite(ctx, tx, config); err != nil {
+		return err
+	}
+
+	// PostgreSQL-specific cleanup (optional)
+	if config.DeallocateAll {
+		if _, err := tx.ExecContext(ctx, "DEALLOCATE ALL"); err != nil {
+			return fmt.Errorf("failed to deallocate prepared statements: %w", err)
+		}
+	}
+
+	if err := preparePostgres(ctx, tx, config); err != nil {
+		return err
+	}
+
+	if err := prepareMySQL(ctx, tx, config); err != nil {
+		return err
+	}
+
+	return runBeginHooks(ctx, tx, config)
+}
+
+// ExecuteVoid runs a function that only returns an error within a transaction with default settings
+func ExecuteVoid(db Executer, fn func(tx *sqlx.Tx) error) error {
+	return ExecuteVoidContext(context.Background(), db, fn)
+}
+
+// ExecuteVoidContext runs a function that only returns an error within a transaction with context support and optional configuration
+func ExecuteVoidContext(ctx context.Context, db Executer, fn func(tx *sqlx.Tx) error, options ...ConfigOption) error {
+	_, err := ExecuteContext(ctx, db, func(tx *sqlx.Tx) (struct{}, error) {
+		return struct{}{}, fn(tx)
+	}, options...)
+	return err
+}
+
+// MustExecute is like Execute but panics if the transaction fails
+func MustExecute[T any](db Executer, txFunc TxFunc[T]) T {
+	return MustExecuteContext(context.Background(), db, txFunc)
+}
+
+// MustExecuteContext is like ExecuteContext but panics if the transaction fails
+func MustExecuteContext[T any](ctx context.Context, db Executer, txFunc TxFunc[T], options ...ConfigOption) T {
+	result, err := ExecuteContext(ctx, db, txFunc, options...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// pair and triple carry multiple results through ExecuteContext
+type pair[A, B any] struct {
+	a A
+	b B
+}
+
+type triple[A, B, C any] struct {
+	a A
+	b B
+	c C
+}
+
+// Execute2 runs a function returning two values within a transaction.
+// All values are zero when an error is returned.
+func Execute2[A, B any](ctx context.Context, db Executer, fn func(*sqlx.Tx) (A, B, error), options ...ConfigOption) (A, B, error) {
+	res, err := ExecuteContext(ctx, db, func(tx *sqlx.Tx) (pair[A, B], error) {
+		a, b, err := fn(tx)
+		return pair[A, B]{a, b}, err
+	}, options...)
+	if err != nil {
+		var zero pair[A, B]
+		return zero.a, zero.b, err
+	}
+	return res.a, res.b, nil
+}
+
+// Execute3 runs a function returning three values within a transaction.
+// All values are zero when an error is returned.
+func Execute3[A, B, C any](ctx context.Context, db Executer, fn func(*sqlx.Tx) (A, B, C, error), options ...ConfigOption) (A, B, C, error) {
+	res, err := ExecuteContext(ctx, db, func(tx *sqlx.Tx) (triple[A, B, C], error) {
+		a, b, c, err := fn(tx)
+		return triple[A, B, C]{a, b, c}, err
+	}, options...)
+	if err != nil {
+		var zero triple[A, B, C]
+		return zero.a, zero.b, zero.c, err
+	}
+	return res.a, res.b, res.c, nil
 }
@@ -1,8 +1,11 @@
 package sqlxtx
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -18,7 +21,6 @@ func TestExecute_Success(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "postgres")
 
 	mock.ExpectBegin()
-	mock.ExpectExec("DEALLOCATE ALL").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"result"}).AddRow(1))
 	mock.ExpectCommit()
 
@@ -41,7 +43,7 @@ func TestExecute_Success(t *testing.T) {
 	}
 }
 
-func TestExecute_Rollback(t *testing.T) {
+func TestExecute_WithOptions(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -52,6 +54,31 @@ func TestExecute_Rollback(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec("DEALLOCATE ALL").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err = Execute(sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithDeallocateAll())
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecute_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
 	mock.ExpectRollback()
 
 	_, err = Execute(sqlxDB, func(tx *sqlx.Tx) (any, error) {
@@ -77,7 +104,6 @@ func TestExecute_Panic(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "postgres")
 
 	mock.ExpectBegin()
-	mock.ExpectExec("DEALLOCATE ALL").WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectRollback()
 
 	defer func() {
@@ -94,3 +120,177 @@ func TestExecute_Panic(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestExecuteContext_Timeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	}, WithTimeout(10*time.Millisecond))
+
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+}
+
+func TestExecuteVoid_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	err = ExecuteVoid(sqlxDB, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec("DELETE FROM users"); err != nil {
+			return err
+		}
+		return txErr
+	})
+
+	if !errors.Is(err, txErr) {
+		t.Errorf("expected test error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMustExecute_PanicsOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	txErr := errors.New("test error")
+	defer func() {
+		r := recover()
+		if err, ok := r.(error); !ok || !errors.Is(err, txErr) {
+			t.Errorf("expected panic with test error, got %v", r)
+		}
+	}()
+
+	MustExecute(sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, txErr
+	})
+}
+
+func TestIsolationLevelShortcuts(t *testing.T) {
+	cases := []struct {
+		option ConfigOption
+		want   sql.IsolationLevel
+	}{
+		{WithSerializable(), sql.LevelSerializable},
+		{WithRepeatableRead(), sql.LevelRepeatableRead},
+		{WithReadCommitted(), sql.LevelReadCommitted},
+		{WithReadUncommitted(), sql.LevelReadUncommitted},
+	}
+
+	for _, c := range cases {
+		config := &Config{}
+		c.option(config)
+		if config.TxOptions == nil || config.TxOptions.Isolation != c.want {
+			t.Errorf("expected isolation level %v, got %+v", c.want, config.TxOptions)
+		}
+	}
+}
+
+func TestExecute2_ZeroValuesOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	name, count, err := Execute2(ctx, sqlxDB, func(tx *sqlx.Tx) (string, int, error) {
+		return "john", 3, nil
+	})
+	if err != nil || name != "john" || count != 3 {
+		t.Errorf("expected (john, 3, nil), got (%v, %v, %v)", name, count, err)
+	}
+
+	name, count, err = Execute2(ctx, sqlxDB, func(tx *sqlx.Tx) (string, int, error) {
+		return "partial", 1, errors.New("test error")
+	})
+	if err == nil || name != "" || count != 0 {
+		t.Errorf("expected zero values with error, got (%q, %v, %v)", name, count, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecute3_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	a, b, c, err := Execute3(context.Background(), sqlxDB, func(tx *sqlx.Tx) (int, string, bool, error) {
+		return 1, "two", true, nil
+	})
+	if err != nil || a != 1 || b != "two" || !c {
+		t.Errorf("expected (1, two, true, nil), got (%v, %v, %v, %v)", a, b, c, err)
+	}
+}
+
+func TestConfigClone_CopiesTxOptions(t *testing.T) {
+	original := newConfig([]ConfigOption{
+		WithSerializable(),
+		WithSearchPath("tenant_a"),
+		WithSessionVariables(map[string]string{"app.user_id": "1"}),
+	})
+
+	clone := original.Clone()
+	clone.TxOptions.Isolation = sql.LevelReadCommitted
+	WithSearchPath("public")(clone)
+	WithSessionVariables(map[string]string{"app.user_id": "2"})(clone)
+
+	if original.TxOptions.Isolation != sql.LevelSerializable {
+		t.Errorf("expected original isolation to stay serializable, got %v", original.TxOptions.Isolation)
+	}
+	if len(original.SearchPath) != 1 {
+		t.Errorf("expected original search path to be unchanged, got %v", original.SearchPath)
+	}
+	if original.SessionVariables["app.user_id"] != "1" {
+		t.Errorf("expected original session variables to be unchanged, got %v", original.SessionVariables)
+	}
+}
@@ -0,0 +1,70 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// UpsertOne inserts row into table, updating updateColumns of the existing row when
+// it conflicts on conflictColumns. Columns come from the struct's db tags and the
+// statement depends on tx.DriverName():
+//
+//   - PostgreSQL (and unrecognized drivers): INSERT ... ON CONFLICT (...) DO UPDATE SET col = EXCLUDED.col
+//   - MySQL: INSERT ... ON DUPLICATE KEY UPDATE col = VALUES(col), where the conflict is
+//     decided by the table's unique keys, so conflictColumns is not used
+//   - SQLite: INSERT OR REPLACE, which replaces the whole row, so updateColumns is not used
+func UpsertOne[T any](ctx context.Context, tx *sqlx.Tx, table string, row T, conflictColumns []string, updateColumns []string) (sql.Result, error) {
+	if err := validateIdentifier("table", table); err != nil {
+		return nil, err
+	}
+	for _, column := range slices.Concat(conflictColumns, updateColumns) {
+		if err := validateIdentifier("column", column); err != nil {
+			return nil, err
+		}
+	}
+
+	columns, err := structColumns(reflect.TypeOf(row))
+	if err != nil {
+		return nil, err
+	}
+	insert := fmt.Sprintf("INTO %s (%s) VALUES (:%s)", table, strings.Join(columns, ", "), strings.Join(columns, ", :"))
+
+	var query string
+	switch driverName := tx.DriverName(); {
+	case isSQLite(driverName):
+		query = "INSERT OR REPLACE " + insert
+	case isMySQL(driverName):
+		if len(updateColumns) == 0 {
+			return nil, fmt.Errorf("upsert into %s needs at least one update column", table)
+		}
+		query = "INSERT " + insert + " ON DUPLICATE KEY UPDATE " + assignColumns(updateColumns, "%s = VALUES(%s)")
+	default:
+		if len(conflictColumns) == 0 || len(updateColumns) == 0 {
+			return nil, fmt.Errorf("upsert into %s needs conflict and update columns", table)
+		}
+		query = fmt.Sprintf("INSERT %s ON CONFLICT (%s) DO UPDATE SET %s",
+			insert, strings.Join(conflictColumns, ", "), assignColumns(updateColumns, "%s = EXCLUDED.%s"))
+	}
+
+	res, err := tx.NamedExecContext(ctx, query, row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert into %s: %w", table, err)
+	}
+	return res, nil
+}
+
+// assignColumns formats each column with format, which takes the column name twice,
+// and joins the results with commas
+func assignColumns(columns []string, format string) string {
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = fmt.Sprintf(format, column, column)
+	}
+	return strings.Join(assignments, ", ")
+}
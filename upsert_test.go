@@ -0,0 +1,73 @@
+package sqlxtx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestUpsertOne_Dialects(t *testing.T) {
+	cases := []struct {
+		driver string
+		query  string
+	}{
+		{"postgres", "INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name"},
+		{"mysql", "INSERT INTO users (id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)"},
+		{"sqlite3", "INSERT OR REPLACE INTO users (id, name) VALUES (?, ?)"},
+	}
+
+	for _, c := range cases {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		sqlxDB := sqlx.NewDb(db, c.driver)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(c.query).WithArgs(1, "a").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		ctx := context.Background()
+		_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+			return UpsertOne(ctx, tx, "users", bulkUser{ID: 1, Name: "a"}, []string{"id"}, []string{"name"})
+		})
+
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", c.driver, err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: there were unfulfilled expectations: %s", c.driver, err)
+		}
+		db.Close()
+	}
+}
+
+func TestUpsertOne_RejectsInvalidColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	ctx := context.Background()
+	_, err = ExecuteContext(ctx, sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return UpsertOne(ctx, tx, "users", bulkUser{ID: 1}, []string{"id"}, []string{"name = 'x'; --"})
+	})
+
+	if err == nil {
+		t.Error("expected an error for an invalid column name")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,235 @@
+package sqlxtx
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// ErrInvalidOption is wrapped by every error returned from Config.Validate
+var ErrInvalidOption = errors.New("invalid transaction option")
+
+// logParametersWarning makes Validate warn about WithPGLogParameters once per process
+var logParametersWarning sync.Once
+
+// Validate checks for invalid values and known bad option combinations.
+// Driver-specific checks only run when DriverName is set, which ExecuteContext
+// does from the database handle before validating.
+func (c *Config) Validate() error {
+	if c.TxOptions != nil && c.TxOptions.ReadOnly && c.TxOptions.Isolation == sql.LevelReadUncommitted {
+		return invalidOption("read-only transactions cannot use read uncommitted isolation")
+	}
+
+	if c.MaxAttempts < 0 {
+		return invalidOption("max attempts must not be negative, got %d", c.MaxAttempts)
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		return invalidOption("jitter fraction must be between 0 and 1, got %g", c.Jitter)
+	}
+	if c.Timeout < 0 {
+		return invalidOption("timeout must not be negative, got %s", c.Timeout)
+	}
+	if c.MaxConnectionWait < 0 {
+		return invalidOption("max connection wait must not be negative, got %s", c.MaxConnectionWait)
+	}
+	if c.StatementTimeout < 0 {
+		return invalidOption("statement timeout must not be negative, got %s", c.StatementTimeout)
+	}
+	if c.SQLiteBusyTimeout < 0 {
+		return invalidOption("busy timeout must not be negative, got %s", c.SQLiteBusyTimeout)
+	}
+	if c.IdleInTransactionTimeout < 0 {
+		return invalidOption("idle in transaction timeout must not be negative, got %s", c.IdleInTransactionTimeout)
+	}
+	if c.LockTimeout < 0 {
+		return invalidOption("lock timeout must not be negative, got %s", c.LockTimeout)
+	}
+
+	if c.MinRowsAffected != nil && *c.MinRowsAffected < 0 {
+		return invalidOption("min rows affected must not be negative, got %d", *c.MinRowsAffected)
+	}
+	if c.MaxRowsAffected != nil && *c.MaxRowsAffected < 0 {
+		return invalidOption("max rows affected must not be negative, got %d", *c.MaxRowsAffected)
+	}
+	if c.MinRowsAffected != nil && c.MaxRowsAffected != nil && *c.MinRowsAffected > *c.MaxRowsAffected {
+		return invalidOption("min rows affected %d exceeds max %d", *c.MinRowsAffected, *c.MaxRowsAffected)
+	}
+
+	if c.ExplainThreshold < 0 {
+		return invalidOption("explain threshold must not be negative, got %s", c.ExplainThreshold)
+	}
+
+	if c.Watcher != nil && c.WatchThreshold <= 0 {
+		return invalidOption("watcher threshold must be positive, got %s", c.WatchThreshold)
+	}
+	if c.HeartbeatInterval < 0 {
+		return invalidOption("heartbeat interval must not be negative, got %s", c.HeartbeatInterval)
+	}
+
+	if c.SavepointName != "" && !savepointNamePattern.MatchString(c.SavepointName) {
+		return invalidOption("invalid savepoint name %q", c.SavepointName)
+	}
+
+	if c.IdempotencyKey != "" && c.IdempotencyStore == nil {
+		return invalidOption("idempotency key %q requires a store", c.IdempotencyKey)
+	}
+
+	if c.Propagation < 0 || c.Propagation > PropagationNested {
+		return invalidOption("unknown propagation %d", c.Propagation)
+	}
+
+	if c.SynchronousCommit != "" && !slices.Contains(synchronousCommitModes, c.SynchronousCommit) {
+		return invalidOption("unknown synchronous_commit mode %q", c.SynchronousCommit)
+	}
+
+	if c.WorkMem != "" && !memorySizePattern.MatchString(c.WorkMem) {
+		return invalidOption("invalid work_mem size %q", c.WorkMem)
+	}
+
+	for _, view := range c.RefreshViews {
+		if !identifierPattern.MatchString(view) {
+			return invalidOption("invalid materialized view name %q", view)
+		}
+	}
+
+	if c.LogParameters {
+		if c.LogParameterMaxLength < -1 {
+			return invalidOption("log parameter max length must be -1 or more, got %d", c.LogParameterMaxLength)
+		}
+		if releaseBuild {
+			logParametersWarning.Do(func() {
+				slog.Warn("sqlxtx: WithPGLogParameters is a debugging option and is enabled in a RELEASE_BUILD build")
+			})
+		}
+	}
+
+	if c.CreatedAtColumn != "" || c.UpdatedAtColumn != "" {
+		if !identifierPattern.MatchString(c.CreatedAtColumn) || !identifierPattern.MatchString(c.UpdatedAtColumn) {
+			return invalidOption("invalid audit column names %q and %q", c.CreatedAtColumn, c.UpdatedAtColumn)
+		}
+	}
+
+	for _, name := range c.RequiredExtensions {
+		if name == "" {
+			return invalidOption("required extension name must not be empty")
+		}
+	}
+
+	for _, schema := range c.SearchPath {
+		if !schemaNamePattern.MatchString(schema) {
+			return invalidOption("invalid schema name %q", schema)
+		}
+	}
+
+	for name := range c.SessionVariables {
+		if !identifierPattern.MatchString(name) {
+			return invalidOption("invalid session variable name %q", name)
+		}
+	}
+
+	for _, name := range c.DeallocateNames {
+		if !savepointNamePattern.MatchString(name) {
+			return invalidOption("invalid prepared statement name %q", name)
+		}
+	}
+
+	for _, name := range c.MySQLDeallocate {
+		if !savepointNamePattern.MatchString(name) {
+			return invalidOption("invalid prepared statement name %q", name)
+		}
+	}
+
+	if c.MySQLCharset != "" && !mysqlCharsetPattern.MatchString(c.MySQLCharset) {
+		return invalidOption("invalid charset %q", c.MySQLCharset)
+	}
+	if c.MySQLTimezone != "" && !mysqlTimezonePattern.MatchString(c.MySQLTimezone) {
+		return invalidOption("invalid time zone %q", c.MySQLTimezone)
+	}
+
+	if c.DriverName != "" {
+		if err := checkDriverSupport(c.DriverName, c); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+		}
+	}
+
+	return nil
+}
+
+// validateEntryPoint rejects options that only take effect through a particular
+// Execute function, so that passing them to any other one fails instead of being ignored
+func (c *Config) validateEntryPoint(entry entryPoint) error {
+	if len(c.QueryInterceptors) > 0 && entry != entryIntercepted {
+		return invalidOption("query interceptors (WithQueryInterceptor, WithPGComment, WithCorrelationID) only apply through ExecuteIntercepted")
+	}
+	if c.ExplainLogger != nil && entry != entryIntercepted {
+		return invalidOption("WithExplainAutoLog only applies through ExecuteIntercepted")
+	}
+	if (c.MinRowsAffected != nil || c.MaxRowsAffected != nil) && entry != entryWithResult {
+		return invalidOption("WithMinRowsAffected and WithMaxRowsAffected only apply through ExecuteWithResult")
+	}
+	if c.Progress != nil && entry != entryContextFunc {
+		return invalidOption("WithProgressCallback only applies through ExecuteWithContextFunc")
+	}
+	if c.SyncCompensation && entry != entryCompensation {
+		return invalidOption("WithSyncCompensation only applies through ExecuteWithCompensation")
+	}
+	if c.OutboxFallback != nil && entry != entryOutbox {
+		return invalidOption("WithOutboxFallback only applies through ExecuteWithOutbox")
+	}
+	if entry == entryLazy {
+		if option := c.lazyUnsupported(); option != "" {
+			return invalidOption("%s does not apply through ExecuteLazy", option)
+		}
+	}
+	return nil
+}
+
+// lazyUnsupported returns the first option set on c that ExecuteLazy does not honour,
+// or an empty string
+func (c *Config) lazyUnsupported() string {
+	switch {
+	case c.MaxAttempts != 0 || c.Backoff != nil || c.Jitter != 0:
+		return "WithRetry"
+	case c.RetryPredicate != nil:
+		return "WithRetryPredicate"
+	case c.AttemptContext != nil:
+		return "WithFreshContextPerRetry"
+	case c.OptimisticLocking:
+		return "WithOptimisticLocking"
+	case c.Timeout != 0:
+		return "WithTimeout"
+	case len(c.Observers) > 0:
+		return "WithObserver"
+	case c.Stats != nil:
+		return "WithStats"
+	case c.OnError != nil:
+		return "WithOnError"
+	case c.Propagation != 0:
+		return "WithPropagation"
+	case c.CreatedAtColumn != "" || c.UpdatedAtColumn != "":
+		return "WithAuditColumns"
+	case c.HeartbeatInterval != 0:
+		return "WithHeartbeat"
+	case c.Watcher != nil:
+		return "WithTxWatcher"
+	case c.CircuitBreaker != nil:
+		return "WithCircuitBreaker"
+	case c.RateLimiter != nil:
+		return "WithRateLimiter"
+	case c.IdempotencyKey != "" || c.IdempotencyStore != nil:
+		return "WithIdempotencyKey"
+	case len(c.MaintenanceStatements) > 0:
+		return "WithPostCommitMaintenance"
+	case c.StatementCache:
+		return "WithStatementCache"
+	}
+	return ""
+}
+
+// invalidOption formats a validation error wrapping ErrInvalidOption
+func invalidOption(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOption, fmt.Sprintf(format, args...))
+}
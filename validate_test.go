@@ -0,0 +1,74 @@
+package sqlxtx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestConfigValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		driver  string
+		options []ConfigOption
+		wantErr bool
+	}{
+		{"defaults", "postgres", nil, false},
+		{"read only read committed", "postgres", []ConfigOption{WithReadOnly(), WithReadCommitted()}, false},
+		{"read only read uncommitted", "postgres", []ConfigOption{WithReadOnly(), WithReadUncommitted()}, true},
+		{"negative timeout", "postgres", []ConfigOption{WithTimeout(-time.Second)}, true},
+		{"deallocate on postgres", "postgres", []ConfigOption{WithDeallocateAll()}, false},
+		{"deallocate on sqlite", "sqlite3", []ConfigOption{WithDeallocateAll()}, true},
+		{"lock timeout on mysql", "mysql", []ConfigOption{WithLockTimeout(time.Second)}, true},
+		{"deallocate without driver", "", []ConfigOption{WithDeallocateAll()}, false},
+		{"synchronous commit remote_apply", "postgres", []ConfigOption{WithSynchronousCommit("remote_apply")}, false},
+		{"unknown synchronous commit", "postgres", []ConfigOption{WithSynchronousCommit("sometimes")}, true},
+		{"negative explain threshold", "postgres", []ConfigOption{WithExplainAutoLog(nil, -time.Second)}, true},
+		{"work mem", "postgres", []ConfigOption{WithWorkMem("256MiB")}, false},
+		{"invalid work mem", "postgres", []ConfigOption{WithWorkMem("256 megs")}, true},
+		{"rows affected range", "postgres", []ConfigOption{WithMinRowsAffected(1), WithMaxRowsAffected(1)}, false},
+		{"inverted rows affected range", "postgres", []ConfigOption{WithMinRowsAffected(2), WithMaxRowsAffected(1)}, true},
+	}
+
+	for _, c := range cases {
+		config := &Config{DriverName: c.driver}
+		for _, option := range c.options {
+			option(config)
+		}
+
+		err := config.Validate()
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.wantErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%s: expected ErrInvalidOption, got %v", c.name, err)
+		}
+	}
+}
+
+func TestExecuteContext_ValidatesBeforeBegin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlite3")
+
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithDeallocateAll(), WithIsolationLevel(sql.LevelSerializable))
+
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,39 @@
+package sqlxtx
+
+import (
+	"context"
+	"time"
+)
+
+// WithTxWatcher calls fn once a transaction has been open for longer than threshold,
+// e.g. to log transactions that hold back vacuum. It is purely diagnostic: the
+// transaction is not cancelled. fn runs in its own goroutine so a slow fn never
+// delays the transaction or the watcher's shutdown.
+func WithTxWatcher(threshold time.Duration, fn func(duration time.Duration, ctx context.Context)) ConfigOption {
+	return func(c *Config) {
+		c.WatchThreshold = threshold
+		c.Watcher = fn
+	}
+}
+
+// startTxWatcher starts the configured watcher and returns a function that stops it.
+// The watcher only holds the start time and ctx, never the transaction itself.
+func startTxWatcher(ctx context.Context, config *Config) (stop func()) {
+	if config.Watcher == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		timer := time.NewTimer(config.WatchThreshold)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			go config.Watcher(time.Since(start), ctx)
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,112 @@
+package sqlxtx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestExecuteContext_TxWatcherFiresForSlowTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	fired := make(chan time.Duration, 1)
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	}, WithTxWatcher(10*time.Millisecond, func(d time.Duration, ctx context.Context) {
+		fired <- d
+	}))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	select {
+	case d := <-fired:
+		if d < 10*time.Millisecond {
+			t.Errorf("expected duration of at least 10ms, got %s", d)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected watcher to fire")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_TxWatcherStopsWithTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	fired := make(chan struct{}, 1)
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		return nil, nil
+	}, WithTxWatcher(20*time.Millisecond, func(d time.Duration, ctx context.Context) {
+		fired <- struct{}{}
+	}))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	select {
+	case <-fired:
+		t.Error("expected watcher not to fire for a fast transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExecuteContext_BlockingTxWatcherDoesNotDelayCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{})
+	_, err = ExecuteContext(context.Background(), sqlxDB, func(tx *sqlx.Tx) (any, error) {
+		<-entered
+		return nil, nil
+	}, WithTxWatcher(time.Millisecond, func(d time.Duration, ctx context.Context) {
+		close(entered)
+		<-release
+	}))
+
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,239 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// xidPattern restricts transaction IDs to characters that need no escaping; 64 bytes
+// is MySQL's limit for the gtrid part of an XID
+var xidPattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+
+// quoteXID renders xid as a string literal for the XA and PREPARE TRANSACTION
+// statements. It does no escaping: every xid is checked against xidPattern first, which
+// admits no quotes or backslashes, so plain single quotes are valid on both MySQL and
+// PostgreSQL without relying on either database's escaping rules.
+func quoteXID(xid string) string {
+	return "'" + xid + "'"
+}
+
+// TwoPhaseCommit runs one branch of a distributed transaction with the database's
+// two-phase commit support: XA START/END/PREPARE/COMMIT/ROLLBACK on MySQL and
+// BEGIN/PREPARE TRANSACTION/COMMIT PREPARED/ROLLBACK PREPARED on PostgreSQL, which
+// needs max_prepared_transactions > 0. The branch holds a dedicated connection from
+// Begin until Prepare or Rollback. It is not safe for concurrent use.
+type TwoPhaseCommit struct {
+	db   *sqlx.DB
+	conn *sqlx.Conn
+	xid  string
+}
+
+// NewTwoPhaseCommit creates a TwoPhaseCommit for db
+func NewTwoPhaseCommit(db *sqlx.DB) *TwoPhaseCommit {
+	return &TwoPhaseCommit{db: db}
+}
+
+// Begin starts the branch xid on a dedicated connection and returns xid, generating a
+// UUID if it is empty. Run the branch's statements on Conn until Prepare.
+func (t *TwoPhaseCommit) Begin(ctx context.Context, xid string) (string, error) {
+	if t.conn != nil {
+		return "", fmt.Errorf("two-phase transaction %s is already in progress", t.xid)
+	}
+	if xid == "" {
+		xid = uuid.NewString()
+	}
+	if err := t.checkXID(xid); err != nil {
+		return "", err
+	}
+
+	conn, err := t.db.Connx(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a connection: %w", err)
+	}
+	start := "BEGIN"
+	if isMySQL(t.db.DriverName()) {
+		start = "XA START " + quoteXID(xid)
+	}
+	if _, err := conn.ExecContext(ctx, start); err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("failed to start two-phase transaction %s: %w", xid, err)
+	}
+
+	t.conn, t.xid = conn, xid
+	return xid, nil
+}
+
+// Conn returns the connection of the branch in progress, or nil
+func (t *TwoPhaseCommit) Conn() *sqlx.Conn {
+	return t.conn
+}
+
+// Prepare ends the branch xid and prepares it for commit (XA END and XA PREPARE, or
+// PREPARE TRANSACTION), releasing its connection. A prepared branch survives crashes
+// and must be finished with Commit or Rollback. An empty xid means the branch in progress.
+func (t *TwoPhaseCommit) Prepare(ctx context.Context, xid string) error {
+	if xid == "" {
+		xid = t.xid
+	}
+	if t.conn == nil || xid != t.xid {
+		return fmt.Errorf("two-phase transaction %q is not in progress", xid)
+	}
+
+	quoted := quoteXID(xid)
+	statements := []string{"PREPARE TRANSACTION " + quoted}
+	if isMySQL(t.db.DriverName()) {
+		statements = []string{"XA END " + quoted, "XA PREPARE " + quoted}
+	}
+	for _, statement := range statements {
+		if _, err := t.conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to prepare two-phase transaction %s: %w", xid, err)
+		}
+	}
+	return t.release()
+}
+
+// Commit commits the prepared branch xid (XA COMMIT or COMMIT PREPARED). It runs on
+// any connection of the database, so it can also finish a branch prepared by
+// another process, e.g. during recovery.
+func (t *TwoPhaseCommit) Commit(ctx context.Context, xid string) error {
+	if err := t.checkXID(xid); err != nil {
+		return err
+	}
+	statement := "COMMIT PREPARED "
+	if isMySQL(t.db.DriverName()) {
+		statement = "XA COMMIT "
+	}
+	if _, err := t.db.ExecContext(ctx, statement+quoteXID(xid)); err != nil {
+		return fmt.Errorf("failed to commit two-phase transaction %s: %w", xid, err)
+	}
+	return nil
+}
+
+// Rollback rolls back the branch xid. A branch still in progress is ended and rolled
+// back on its connection; any other xid is treated as prepared (XA ROLLBACK or
+// ROLLBACK PREPARED).
+func (t *TwoPhaseCommit) Rollback(ctx context.Context, xid string) error {
+	if err := t.checkXID(xid); err != nil {
+		return err
+	}
+	quoted := quoteXID(xid)
+	mysqlXA := isMySQL(t.db.DriverName())
+
+	if t.conn != nil && xid == t.xid {
+		statement := "ROLLBACK"
+		if mysqlXA {
+			// XA END fails if a failed Prepare already ended the branch; XA ROLLBACK works either way
+			_, _ = t.conn.ExecContext(ctx, "XA END "+quoted)
+			statement = "XA ROLLBACK " + quoted
+		}
+		var err error
+		if _, execErr := t.conn.ExecContext(ctx, statement); execErr != nil {
+			err = fmt.Errorf("failed to roll back two-phase transaction %s: %w", xid, execErr)
+		}
+		return errors.Join(err, t.release())
+	}
+
+	statement := "ROLLBACK PREPARED "
+	if mysqlXA {
+		statement = "XA ROLLBACK "
+	}
+	if _, err := t.db.ExecContext(ctx, statement+quoted); err != nil {
+		return fmt.Errorf("failed to roll back two-phase transaction %s: %w", xid, err)
+	}
+	return nil
+}
+
+// checkXID rejects unsupported drivers and transaction IDs that are not plain tokens
+func (t *TwoPhaseCommit) checkXID(xid string) error {
+	if driverName := t.db.DriverName(); !isMySQL(driverName) && !isPostgres(driverName) {
+		return unsupportedOption("two-phase commit", driverName)
+	}
+	if !xidPattern.MatchString(xid) {
+		return invalidOption("invalid transaction id %q", xid)
+	}
+	return nil
+}
+
+// release returns the branch's connection to the pool
+func (t *TwoPhaseCommit) release() error {
+	err := t.conn.Close()
+	t.conn, t.xid = nil, ""
+	return err
+}
+
+// TwoPhaseCommitCoordinator commits a distributed transaction across several
+// databases with two-phase commit. Each arm runs on its own database and is
+// prepared; only when every arm is prepared are they committed. If an arm fails
+// before that, all arms are rolled back. A failure while committing leaves the
+// remaining prepared arms in doubt until they are committed, e.g. by a recovery job
+// using TwoPhaseCommit.Commit with the reported XIDs. The zero value is ready to use.
+type TwoPhaseCommitCoordinator struct {
+	arms []xaArm
+}
+
+type xaArm struct {
+	db *sqlx.DB
+	fn func(ctx context.Context, conn *sqlx.Conn) error
+}
+
+// Add appends an arm that runs fn on a connection of db inside its branch
+func (c *TwoPhaseCommitCoordinator) Add(db *sqlx.DB, fn func(ctx context.Context, conn *sqlx.Conn) error) *TwoPhaseCommitCoordinator {
+	c.arms = append(c.arms, xaArm{db: db, fn: fn})
+	return c
+}
+
+// Execute runs every arm under the XID <xid>-<arm index>, generating xid as a UUID if
+// it is empty, and commits the arms once all of them are prepared. It returns the
+// first failure, with any rollback errors appended.
+func (c *TwoPhaseCommitCoordinator) Execute(ctx context.Context, xid string) error {
+	if xid == "" {
+		xid = uuid.NewString()
+	}
+
+	branches := make([]*TwoPhaseCommit, 0, len(c.arms))
+	xids := make([]string, 0, len(c.arms))
+	for i, arm := range c.arms {
+		branch := NewTwoPhaseCommit(arm.db)
+		armXID, err := branch.Begin(ctx, fmt.Sprintf("%s-%d", xid, i))
+		if err == nil {
+			branches, xids = append(branches, branch), append(xids, armXID)
+			if err = arm.fn(ctx, branch.Conn()); err == nil {
+				err = branch.Prepare(ctx, armXID)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("two-phase commit arm %d failed: %w", i, err)
+			if rollbackErr := rollbackArms(ctx, branches, xids); rollbackErr != nil {
+				err = fmt.Errorf("%w (rollback error: %v)", err, rollbackErr)
+			}
+			return err
+		}
+	}
+
+	var errs error
+	for i, branch := range branches {
+		if err := branch.Commit(ctx, xids[i]); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("arm %d (xid %s): %w", i, xids[i], err))
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("two-phase commit left prepared transactions in doubt: %w", errs)
+	}
+	return nil
+}
+
+// rollbackArms rolls back every begun or prepared arm
+func rollbackArms(ctx context.Context, branches []*TwoPhaseCommit, xids []string) error {
+	var errs error
+	for i, branch := range branches {
+		if err := branch.Rollback(ctx, xids[i]); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("arm %d: %w", i, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,174 @@
+package sqlxtx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTwoPhaseCommit_MySQL(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("XA START 'order-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE stock SET qty = qty - 1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("XA END 'order-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("XA PREPARE 'order-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("XA COMMIT 'order-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	xa := NewTwoPhaseCommit(sqlx.NewDb(db, "mysql"))
+	xid, err := xa.Begin(ctx, "order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := xa.Conn().ExecContext(ctx, "UPDATE stock SET qty = qty - 1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := xa.Prepare(ctx, xid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if xa.Conn() != nil {
+		t.Error("expected the connection to be released after Prepare")
+	}
+	if err := xa.Commit(ctx, xid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTwoPhaseCommit_PostgresGeneratesXID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("PREPARE TRANSACTION '[0-9a-f-]{36}'").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK PREPARED '[0-9a-f-]{36}'").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	xa := NewTwoPhaseCommit(sqlx.NewDb(db, "postgres"))
+	xid, err := xa.Begin(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := uuid.Parse(xid); err != nil {
+		t.Errorf("expected a generated UUID, got %q", xid)
+	}
+	if err := xa.Prepare(ctx, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := xa.Rollback(ctx, xid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTwoPhaseCommit_RejectsSQLite(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	_, err = NewTwoPhaseCommit(sqlx.NewDb(db, "sqlite3")).Begin(context.Background(), "")
+	if !errors.Is(err, ErrDriverNotSupported) {
+		t.Errorf("expected ErrDriverNotSupported, got %v", err)
+	}
+}
+
+func TestTwoPhaseCommitCoordinator_Commit(t *testing.T) {
+	orders, ordersMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer orders.Close()
+	billing, billingMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer billing.Close()
+
+	ordersMock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+	ordersMock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	ordersMock.ExpectExec("PREPARE TRANSACTION 'checkout-0'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("XA START 'checkout-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("INSERT INTO invoices").WillReturnResult(sqlmock.NewResult(1, 1))
+	billingMock.ExpectExec("XA END 'checkout-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("XA PREPARE 'checkout-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+	ordersMock.ExpectExec("COMMIT PREPARED 'checkout-0'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("XA COMMIT 'checkout-1'").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	var coordinator TwoPhaseCommitCoordinator
+	err = coordinator.
+		Add(sqlx.NewDb(orders, "postgres"), func(ctx context.Context, conn *sqlx.Conn) error {
+			_, err := conn.ExecContext(ctx, "INSERT INTO orders")
+			return err
+		}).
+		Add(sqlx.NewDb(billing, "mysql"), func(ctx context.Context, conn *sqlx.Conn) error {
+			_, err := conn.ExecContext(ctx, "INSERT INTO invoices")
+			return err
+		}).
+		Execute(context.Background(), "checkout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := ordersMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if err := billingMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTwoPhaseCommitCoordinator_RollsBackAllArms(t *testing.T) {
+	orders, ordersMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer orders.Close()
+	billing, billingMock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer billing.Close()
+
+	ordersMock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+	ordersMock.ExpectExec("PREPARE TRANSACTION 'checkout-0'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("BEGIN").WillReturnResult(sqlmock.NewResult(0, 0))
+	ordersMock.ExpectExec("ROLLBACK PREPARED 'checkout-0'").WillReturnResult(sqlmock.NewResult(0, 0))
+	billingMock.ExpectExec("ROLLBACK").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	boom := errors.New("card declined")
+	var coordinator TwoPhaseCommitCoordinator
+	err = coordinator.
+		Add(sqlx.NewDb(orders, "postgres"), func(ctx context.Context, conn *sqlx.Conn) error { return nil }).
+		Add(sqlx.NewDb(billing, "postgres"), func(ctx context.Context, conn *sqlx.Conn) error { return boom }).
+		Execute(context.Background(), "checkout")
+	if !errors.Is(err, boom) {
+		t.Errorf("expected the arm's error, got %v", err)
+	}
+
+	if err := ordersMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	if err := billingMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}